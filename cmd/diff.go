@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/0xste/validator-stats/internal/snapshot"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// diffCommand compares the from and to snapshots, defaulting to the two latest of the same kind in SNAPSHOT_DIR
+func diffCommand(args []string) error {
+	if len(args) > 2 {
+		return fmt.Errorf("diff takes at most 2 snapshots, got %d", len(args))
+	}
+	var from, to string
+	if len(args) > 0 {
+		from = args[0]
+	}
+	if len(args) > 1 {
+		to = args[1]
+	}
+	if from == "" || to == "" {
+		paths, err := snapshot.List(viper.GetString(configSnapshotDir))
+		if err != nil {
+			return err
+		}
+		// only snapshots of the same kind are compared, a merged scan with a merged scan and a shard with itself
+		if from != "" {
+			paths = snapshot.OfKind(paths, snapshot.KindOf(from))
+		} else if len(paths) > 0 {
+			paths = snapshot.OfKind(paths, snapshot.KindOf(paths[len(paths)-1]))
+		}
+		if from != "" && len(paths) > 0 {
+			to = paths[len(paths)-1]
+		} else if len(paths) < 2 {
+			return fmt.Errorf("need 2 snapshots of the same kind in %s to diff, found %d", viper.GetString(configSnapshotDir), len(paths))
+		} else {
+			to, from = paths[len(paths)-1], paths[len(paths)-2]
+		}
+	}
+	fromSnapshot, err := snapshot.Load(from)
+	if err != nil {
+		return err
+	}
+	toSnapshot, err := snapshot.Load(to)
+	if err != nil {
+		return err
+	}
+	changes := snapshot.Diff(fromSnapshot, toSnapshot)
+	log.Printf("found %d changes between %s and %s\n", len(changes), from, to)
+
+	diffFile, err := os.Create(viper.GetString(configDiffFile))
+	if err != nil {
+		return errors.Wrap(err, "failed to create diff file")
+	}
+	defer diffFile.Close()
+	diffWriter := csv.NewWriter(diffFile)
+	defer diffWriter.Flush()
+	if err := diffWriter.Write([]string{"pubkey", "change", "from", "to", "detail", "group"}); err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if err := diffWriter.Write([]string{change.Pubkey, string(change.Kind), change.From, change.To, change.Detail, change.Group}); err != nil {
+			return errors.Wrap(err, "error writing record to file")
+		}
+	}
+	return nil
+}
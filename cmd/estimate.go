@@ -0,0 +1,26 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// estimateCommand resolves the pubkeys of a scan and logs how long checking them will take without querying beaconcha.in
+func estimateCommand(args []string) error {
+	processStart := time.Now()
+	groups, err := getGroups()
+	if err != nil {
+		return err
+	}
+	promClient, err := getSourcePromClient(groups)
+	if err != nil {
+		return err
+	}
+	targets, err := getTargets(groups)
+	if err != nil {
+		return err
+	}
+	newValidatorClient(promClient).GetEstimatedDuration(len(targets))
+	log.Printf("retrieving pubkeys took %s\n", time.Since(processStart))
+	return nil
+}
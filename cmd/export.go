@@ -0,0 +1,66 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/0xste/validator-stats/internal/export"
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+func exportCommand(args []string) error {
+	severities, err := getSeverities()
+	if err != nil {
+		return err
+	}
+
+	rules, err := export.Rules(severities)
+	if err != nil {
+		return errors.Wrap(err, "failed to render rules")
+	}
+	if err := os.WriteFile(viper.GetString(configRulesFile), rules, 0o644); err != nil {
+		return errors.Wrap(err, "failed to write rules file")
+	}
+
+	dashboard, err := export.Dashboard(severities)
+	if err != nil {
+		return errors.Wrap(err, "failed to render dashboard")
+	}
+	if err := os.WriteFile(viper.GetString(configDashboardFile), dashboard, 0o644); err != nil {
+		return errors.Wrap(err, "failed to write dashboard file")
+	}
+	log.Printf("wrote %s and %s\n", viper.GetString(configRulesFile), viper.GetString(configDashboardFile))
+	return nil
+}
+
+// getSeverities overrides the default alert thresholds with issue_type=warning:critical pairs
+func getSeverities() (map[validator.IssueType]export.Severity, error) {
+	severities := make(map[validator.IssueType]export.Severity)
+	for issueType, severity := range export.DefaultSeverities {
+		severities[issueType] = severity
+	}
+	for _, threshold := range getList(configAlertThreshold) {
+		issueType, levels, _ := strings.Cut(threshold, "=")
+		warning, critical, _ := strings.Cut(levels, ":")
+		var severity export.Severity
+		var err error
+		if severity.Warning, err = strconv.Atoi(strings.TrimSpace(warning)); err != nil {
+			return nil, errors.Wrapf(err, "invalid warning threshold for %s", issueType)
+		}
+		if critical != "" {
+			if severity.Critical, err = strconv.Atoi(strings.TrimSpace(critical)); err != nil {
+				return nil, errors.Wrapf(err, "invalid critical threshold for %s", issueType)
+			}
+		}
+		issue, ok := validator.LookupIssue(validator.IssueType(strings.TrimSpace(issueType)))
+		if !ok || issue.Status {
+			return nil, errors.Errorf("unknown issue type %s, see validator-stats issues", issueType)
+		}
+		severities[issue.Type] = severity
+	}
+	return severities, nil
+}
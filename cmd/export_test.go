@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/0xste/validator-stats/internal/export"
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/spf13/viper"
+)
+
+func TestGetSeverities(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds string
+		want       map[validator.IssueType]export.Severity
+		wantErr    bool
+	}{
+		{name: "defaults", want: map[validator.IssueType]export.Severity{"missed_attestation": {Warning: 10, Critical: 50}, "missed_block": {Warning: 1, Critical: 2}}},
+		{name: "warning and critical", thresholds: "missed_attestation=5:20", want: map[validator.IssueType]export.Severity{"missed_attestation": {Warning: 5, Critical: 20}, "missed_block": {Warning: 1, Critical: 2}}},
+		{name: "warning only disables critical", thresholds: "missed_attestation=5", want: map[validator.IssueType]export.Severity{"missed_attestation": {Warning: 5}}},
+		{name: "several with spaces", thresholds: " missed_sync = 0:10 , missed_block=3:4", want: map[validator.IssueType]export.Severity{"missed_sync": {Critical: 10}, "missed_block": {Warning: 3, Critical: 4}}},
+		{name: "unknown issue type", thresholds: "missed_everything=1:2", wantErr: true},
+		{name: "status issue", thresholds: "status_active_offline=1:2", wantErr: true},
+		{name: "invalid warning", thresholds: "missed_attestation=x:2", wantErr: true},
+		{name: "invalid critical", thresholds: "missed_attestation=1:x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set(configAlertThreshold, tt.thresholds)
+			defer viper.Set(configAlertThreshold, "")
+			got, err := getSeverities()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(export.DefaultSeverities) {
+				t.Errorf("got %d severities, want one per issue type %d", len(got), len(export.DefaultSeverities))
+			}
+			for issueType, want := range tt.want {
+				if got[issueType] != want {
+					t.Errorf("%s got %+v, want %+v", issueType, got[issueType], want)
+				}
+			}
+		})
+	}
+}
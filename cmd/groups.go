@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/notify"
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// group is a named set of validators from the groups of the config file, each with its own source and policy
+type group struct {
+	Name   string `mapstructure:"name"`
+	Source string `mapstructure:"source"`
+	// Pubkeys and PubkeysFile are read for source file
+	Pubkeys     []string `mapstructure:"pubkeys"`
+	PubkeysFile string   `mapstructure:"pubkeys_file"`
+	// Prom overrides the PROM_ discovery config for source prom
+	Prom              discoveryOverrides `mapstructure:"prom"`
+	WithdrawalAddress string             `mapstructure:"withdrawal_address"`
+	FeeRecipient      string             `mapstructure:"fee_recipient"`
+	Rules             ruleOverrides      `mapstructure:"rules"`
+	Notify            []notifyTarget     `mapstructure:"notify"`
+}
+
+type discoveryOverrides struct {
+	Preset       string `mapstructure:"preset"`
+	Query        string `mapstructure:"query"`
+	PubkeyLabel  string `mapstructure:"pubkey_label"`
+	NetworkLabel string `mapstructure:"network_label"`
+	Network      string `mapstructure:"network"`
+}
+
+type ruleOverrides struct {
+	AttestationEffectivenessMin *float64 `mapstructure:"attestation_effectiveness_min"`
+	AttestationEfficiencyMax    *float64 `mapstructure:"attestation_efficiency_max"`
+}
+
+type notifyTarget struct {
+	Webhook string `mapstructure:"webhook"`
+}
+
+// getGroups reads the groups of the config file, without any there is a single unnamed group reading from SOURCE
+func getGroups() ([]group, error) {
+	var groups []group
+	if err := viper.UnmarshalKey("groups", &groups); err != nil {
+		return nil, errors.Wrapf(err, "invalid groups in %s", viper.ConfigFileUsed())
+	}
+	if len(groups) == 0 {
+		return []group{{Source: viper.GetString(configSource), PubkeysFile: viper.GetString(configFile)}}, nil
+	}
+
+	seen := make(map[string]bool)
+	for i := range groups {
+		g := &groups[i]
+		if g.Name == "" {
+			return nil, fmt.Errorf("group %d has no name", i+1)
+		}
+		if seen[g.Name] {
+			return nil, fmt.Errorf("group %q is defined twice", g.Name)
+		}
+		seen[g.Name] = true
+		if g.Source == "" {
+			g.Source = "prom"
+			if len(g.Pubkeys) > 0 || g.PubkeysFile != "" {
+				g.Source = "file"
+			}
+		}
+		if g.Source != "file" && g.Source != "prom" {
+			return nil, fmt.Errorf("group %q has unknown source %q", g.Name, g.Source)
+		}
+	}
+	return groups, nil
+}
+
+// policy applies the overrides of the group to the configured rules, the unnamed group uses the client rules
+func (g group) policy() *validator.Policy {
+	if g.Name == "" {
+		return nil
+	}
+	policy := &validator.Policy{
+		Rules: validator.Rules{
+			MinAttestationEffectiveness: viper.GetFloat64(configAttestationEffectivenessMin),
+			MaxAttestationEfficiency:    viper.GetFloat64(configAttestationEfficiencyMax),
+		},
+		WithdrawalAddress: g.WithdrawalAddress,
+		FeeRecipient:      g.FeeRecipient,
+	}
+	if g.Rules.AttestationEffectivenessMin != nil {
+		policy.MinAttestationEffectiveness = *g.Rules.AttestationEffectivenessMin
+	}
+	if g.Rules.AttestationEfficiencyMax != nil {
+		policy.MaxAttestationEfficiency = *g.Rules.AttestationEfficiencyMax
+	}
+	return policy
+}
+
+// notifyGroups posts a summary of each group to its notification targets, failures are logged and don't fail the scan
+func notifyGroups(groups []group, taken time.Time, healths []*validator.Health) {
+	for _, g := range groups {
+		if len(g.Notify) == 0 {
+			continue
+		}
+		summary := notify.Summarize(g.Name, taken, healths)
+		for _, target := range g.Notify {
+			if target.Webhook == "" {
+				continue
+			}
+			if err := notify.Webhook(context.Background(), target.Webhook, summary); err != nil {
+				log.Printf("failed to notify group %s: %s\n", g.Name, err)
+			}
+		}
+	}
+}
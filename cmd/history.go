@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/store"
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+func historyCommand(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("history takes a pubkey")
+	}
+	return writeHistory(os.Stdout, args[0])
+}
+
+func slashedCommand(args []string) error {
+	return writeSlashed(os.Stdout)
+}
+
+// writeHistory writes every recorded run of pubkey
+func writeHistory(w io.Writer, pubkey string) error {
+	history, err := store.Open(viper.GetString(configStoreFile))
+	if err != nil {
+		return err
+	}
+	defer history.Close()
+	records, err := history.History(pubkey)
+	if err != nil {
+		return err
+	}
+
+	historyWriter := csv.NewWriter(w)
+	defer historyWriter.Flush()
+	if err := historyWriter.Write([]string{"timestamp", "status", "balance", "slashed", "withdrawal", "conditions", "group"}); err != nil {
+		return err
+	}
+	for _, record := range records {
+		counts := make(map[validator.IssueType]int)
+		var issueTypes []string
+		for _, condition := range record.Conditions {
+			if _, ok := counts[condition.IssueType]; !ok {
+				issueTypes = append(issueTypes, string(condition.IssueType))
+			}
+			counts[condition.IssueType] += condition.Occurrences()
+		}
+		var conditions []string
+		for _, issueType := range issueTypes {
+			conditions = append(conditions, fmt.Sprintf("%s=%d", issueType, counts[validator.IssueType(issueType)]))
+		}
+		err := historyWriter.Write([]string{
+			record.Taken.String(),
+			record.Data.Status,
+			strconv.FormatInt(record.Data.Balance, 10),
+			strconv.FormatBool(record.Data.Slashed),
+			record.Data.Withdrawalcredentials,
+			strings.Join(conditions, ";"),
+			record.Group,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSlashed writes the validators seen slashed from HISTORY_SINCE through HISTORY_UNTIL, default the last 30 days
+func writeSlashed(w io.Writer) error {
+	since, until := time.Now().Add(-30*24*time.Hour), time.Now()
+	var err error
+	if s := viper.GetString(configHistorySince); s != "" {
+		if since, err = time.Parse("2006-01-02", s); err != nil {
+			return errors.Wrapf(err, "invalid %s", configHistorySince)
+		}
+	}
+	if u := viper.GetString(configHistoryUntil); u != "" {
+		if until, err = time.Parse("2006-01-02", u); err != nil {
+			return errors.Wrapf(err, "invalid %s", configHistoryUntil)
+		}
+		// the store's range ends before until, include the whole day like validator.DateWindow
+		until = until.AddDate(0, 0, 1)
+	}
+
+	history, err := store.Open(viper.GetString(configStoreFile))
+	if err != nil {
+		return err
+	}
+	defer history.Close()
+	records, err := history.Slashed(since, until)
+	if err != nil {
+		return err
+	}
+
+	slashedWriter := csv.NewWriter(w)
+	defer slashedWriter.Flush()
+	if err := slashedWriter.Write([]string{"pubkey", "index", "status", "first_seen", "group"}); err != nil {
+		return err
+	}
+	for _, record := range records {
+		err := slashedWriter.Write([]string{record.Data.Pubkey, strconv.Itoa(record.Data.Validatorindex), record.Data.Status, record.Taken.String(), record.Group})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/spf13/viper"
+)
+
+// inspectCommand checks a single validator and prints its state and conditions
+func inspectCommand(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("inspect takes a pubkey")
+	}
+	client := newValidatorClient(nil)
+	health, err := client.GetValidatorHealth(validator.Target{Pubkey: args[0]}, viper.GetDuration(configTimeRange))
+	if err != nil {
+		return err
+	}
+
+	info := health.Info.Data
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "pubkey\t%s\n", info.Pubkey)
+	fmt.Fprintf(w, "index\t%d\n", info.Validatorindex)
+	fmt.Fprintf(w, "status\t%s\n", info.Status)
+	fmt.Fprintf(w, "slashed\t%t\n", info.Slashed)
+	fmt.Fprintf(w, "balance\t%d\n", info.Balance)
+	fmt.Fprintf(w, "withdrawal\t%s\n", info.Withdrawalcredentials)
+	if health.Attestation != nil {
+		fmt.Fprintf(w, "attestation_effectiveness\t%s\n", formatOptional(health.Attestation.Effectiveness))
+		fmt.Fprintf(w, "attestation_efficiency\t%s\n", formatOptional(health.Attestation.Efficiency))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	conditions := health.Conditions[info.Pubkey]
+	fmt.Printf("\n%d conditions within %s\n", len(conditions), viper.GetDuration(configTimeRange))
+	if len(conditions) == 0 {
+		return nil
+	}
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "day\tissue_type\tcount")
+	for _, condition := range conditions {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", condition.Day.Format("2006-01-02"), condition.IssueType, strconv.Itoa(condition.Count))
+	}
+	return w.Flush()
+}
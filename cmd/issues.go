@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/0xste/validator-stats/internal/validator"
+)
+
+// issuesCommand prints the issue type catalogue with the alert thresholds export would use
+func issuesCommand(args []string) error {
+	severities, err := getSeverities()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "issue_type\tdaily\twarning\tcritical\tdescription")
+	for _, issue := range validator.Issues {
+		warning, critical := "-", "-"
+		if !issue.Status {
+			warning, critical = threshold(severities[issue.Type].Warning), threshold(severities[issue.Type].Critical)
+		}
+		fmt.Fprintf(w, "%s\t%t\t%s\t%s\t%s\n", issue.Type, issue.Daily, warning, critical, issue.Description)
+	}
+	return w.Flush()
+}
+
+// threshold leaves disabled severities empty
+func threshold(count int) string {
+	if count <= 0 {
+		return ""
+	}
+	return strconv.Itoa(count)
+}
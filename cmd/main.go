@@ -1,21 +1,20 @@
 package main
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"strconv"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/0xste/validator-stats/internal/validator"
 	"github.com/0xste/validator-stats/pkg/beacon"
 	"github.com/0xste/validator-stats/pkg/prom"
 	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
-	"gopkg.in/yaml.v2"
 )
 
 var (
@@ -23,148 +22,392 @@ var (
 	configOutFile   = "OUT_FILE"
 	configInfoFile  = "INFO_FILE"
 	configTimeRange = "TIME_RANGE"
-	configMode      = "RUN_MODE"
+	configSource    = "SOURCE"
 
-	// mode == file
+	// consecutive days of the same issue merged into one row, alongside OUT_FILE
+	configIncidentsFile = "INCIDENTS_FILE"
+
+	// validators a scan couldn't check and why, rewritten every scan
+	configErrorsFile = "ERRORS_FILE"
+
+	// benchmark against ETH.STORE, disabled unless a file is set
+	configBenchmarkFile      = "BENCHMARK_FILE"
+	configBenchmarkThreshold = "BENCHMARK_THRESHOLD"
+
+	// per slot drill-down of missed attestations, disabled unless a file is set
+	configDetailsFile = "DETAILS_FILE"
+
+	// local prometheus telemetry for validators with conditions, disabled unless a file is set
+	configEvidenceFile = "EVIDENCE_FILE"
+
+	// push the results of a run, auth follows the PROM_ auth config
+	configPushgatewayEndpoint = "PUSHGATEWAY_ENDPOINT"
+	configPushgatewayJob      = "PUSHGATEWAY_JOB"
+	configRemoteWriteEndpoint = "REMOTE_WRITE_ENDPOINT"
+
+	// markdown summary, disabled unless a file is set
+	configMarkdownFile = "MARKDOWN_FILE"
+	configMarkdownTop  = "MARKDOWN_TOP"
+
+	// aggregate per group or label, disabled in scan unless a file is set
+	configSummaryFile = "SUMMARY_FILE"
+	configSummaryBy   = "SUMMARY_BY"
+
+	// attestation, proposal and sync rates per window, disabled in scan unless a file is set
+	configSLAFile    = "SLA_FILE"
+	configSLAWindows = "SLA_WINDOWS"
+
+	// static html report, disabled unless a dir is set
+	configHTMLDir = "HTML_DIR"
+
+	// prometheus labels written as extra columns of out.csv and info.csv
+	configLabelColumns = "LABEL_COLUMNS"
+
+	// cross validator outage correlation, disabled unless a file is set
+	configCorrelationFile          = "CORRELATION_FILE"
+	configCorrelationLabels        = "CORRELATION_LABELS"
+	configCorrelationMinValidators = "CORRELATION_MIN_VALIDATORS"
+
+	// rule thresholds
+	configAttestationEffectivenessMin = "ATTESTATION_EFFECTIVENESS_MIN"
+	configAttestationEfficiencyMax    = "ATTESTATION_EFFICIENCY_MAX"
+
+	// export, prometheus alert rules and grafana dashboard over the pushed metrics
+	configRulesFile      = "RULES_FILE"
+	configDashboardFile  = "DASHBOARD_FILE"
+	configAlertThreshold = "ALERT_THRESHOLDS"
+
+	// snapshots of each scan, diff compares two of them
+	configSnapshotDir = "SNAPSHOT_DIR"
+	configDiffFile    = "DIFF_FILE"
+
+	// embedded history of every scan, history and slashed query it
+	configStoreFile    = "STORE_FILE"
+	configHistorySince = "HISTORY_SINCE"
+	configHistoryUntil = "HISTORY_UNTIL"
+
+	// serve, an HTTP API over the latest scan
+	configServeAddress  = "SERVE_ADDRESS"
+	configServeInterval = "SERVE_INTERVAL"
+
+	// source == file
 	configFile = "CONFIG_FILE"
 
-	// mode == prom
+	// source == prom
 	configPromUser     = "PROM_USER"
 	configPromPassword = "PROM_PASSWORD"
 	configPromEndpoint = "PROM_ENDPOINT"
+
+	// source == prom, optional auth
+	configPromBearerToken = "PROM_BEARER_TOKEN"
+	configPromTLSCert     = "PROM_TLS_CERT"
+	configPromTLSKey      = "PROM_TLS_KEY"
+	configPromTLSCA       = "PROM_TLS_CA"
+	configPromHeaders     = "PROM_HEADERS"
+
+	// source == prom, validator discovery
+	configPromPreset       = "PROM_PRESET"
+	configPromQuery        = "PROM_QUERY"
+	configPromPubkeyLabel  = "PROM_PUBKEY_LABEL"
+	configPromNetworkLabel = "PROM_NETWORK_LABEL"
+	configPromNetwork      = "PROM_NETWORK"
+
+	// beaconcha.in, e.g. https://holesky.beaconcha.in for testnet validators
+	configBeaconEndpoint = "BEACON_ENDPOINT"
+
+	// split the validators between processes, each scans the pubkeys hashed to its index
+	configShardIndex = "SHARD_INDEX"
+	configShardCount = "SHARD_COUNT"
+
+	// checkpoint of a scan in progress, --resume continues it
+	configCheckpointFile = "CHECKPOINT_FILE"
+	configResume         = "RESUME"
+
+	// how often a scan logs its progress
+	configProgressInterval = "PROGRESS_INTERVAL"
+
+	// yaml or toml file of settings and validator groups, settings use the lower case env var names e.g. out_file
+	configSettings = "CONFIG"
+
+	// deprecated, RUN_MODE=file|prom without a command runs scan from that source
+	configMode = "RUN_MODE"
+)
+
+// usages is the help text of each config key, every key can be set as an env var or a --flag of the same name
+var usages = map[string]string{
+	configSettings:                    "yaml or toml file of settings and validator groups",
+	configShardIndex:                  "shard of the validators this process scans, from 0",
+	configShardCount:                  "number of shards the validators are split into",
+	configCheckpointFile:              "jsonl of the validators a scan completed, removed when the scan finishes, default ./checkpoint.jsonl or ./checkpoint-<SHARD_INDEX>.jsonl when sharded",
+	configResume:                      "skip the validators in CHECKPOINT_FILE and append to the existing outputs",
+	configProgressInterval:            "how often a scan logs checked/total validators, throughput, failures and eta",
+	configOutFile:                     "csv of the conditions of every validator",
+	configInfoFile:                    "csv of the state of every validator",
+	configIncidentsFile:               "csv of the consecutive days of each daily issue merged into incidents",
+	configErrorsFile:                  "csv of the validators that couldn't be checked and why, the scan exits 3 when any are written",
+	configTimeRange:                   "how far back conditions are reported",
+	configSource:                      "where pubkeys come from, file or prom",
+	configBenchmarkFile:               "csv comparing each validator with ETH.STORE, disabled when empty",
+	configBenchmarkThreshold:          "percentage below ETH.STORE before a validator is flagged",
+	configDetailsFile:                 "csv of the duties behind missed attestations, disabled when empty",
+	configEvidenceFile:                "csv of local prometheus telemetry for conditions, disabled when empty",
+	configPushgatewayEndpoint:         "pushgateway to push the results to, disabled when empty",
+	configPushgatewayJob:              "pushgateway job the results replace",
+	configRemoteWriteEndpoint:         "prometheus remote-write endpoint for the results, disabled when empty",
+	configMarkdownFile:                "markdown summary of the scan, disabled when empty",
+	configMarkdownTop:                 "validators listed in the markdown summary",
+	configHTMLDir:                     "dir of the html report, disabled when empty",
+	configSummaryFile:                 "csv or .json summary per group, disabled in scan when empty",
+	configSummaryBy:                   "group, or a label to summarize by instead e.g. operator",
+	configSLAFile:                     "csv or .json of the duty rates per validator and group, disabled in scan when empty",
+	configSLAWindows:                  "months, or comma separated from:until dates e.g. 2026-07-01:2026-09-30",
+	configLabelColumns:                "comma separated prometheus labels added as columns",
+	configCorrelationFile:             "csv of outages shared between validators, disabled when empty",
+	configCorrelationLabels:           "comma separated labels validators are clustered by",
+	configCorrelationMinValidators:    "validators an outage needs to be reported",
+	configAttestationEffectivenessMin: "lowest acceptable attestation effectiveness percentage",
+	configAttestationEfficiencyMax:    "highest acceptable attestation efficiency, 1 is optimal",
+	configRulesFile:                   "prometheus alert rule file to write",
+	configDashboardFile:               "grafana dashboard file to write",
+	configAlertThreshold:              "alert thresholds as issue_type=warning:critical, comma separated",
+	configSnapshotDir:                 "dir of the snapshot of every scan",
+	configDiffFile:                    "csv of the changes between two snapshots",
+	configStoreFile:                   "embedded database of the history of every scan",
+	configHistorySince:                "start of the range e.g. 2026-10-01, default 30 days ago",
+	configHistoryUntil:                "end of the range e.g. 2026-10-31, default now",
+	configServeAddress:                "address the HTTP API listens on",
+	configServeInterval:               "wait between scans",
+	configFile:                        "yml list of pubkeys for source file",
+	configPromUser:                    "prometheus basic auth user",
+	configPromPassword:                "prometheus basic auth password",
+	configPromEndpoint:                "prometheus datasource e.g. https://prometheus.example.com/api/v1/prom/",
+	configPromBearerToken:             "prometheus bearer token",
+	configPromTLSCert:                 "PEM client cert for prometheus mTLS",
+	configPromTLSKey:                  "PEM client key for prometheus mTLS",
+	configPromTLSCA:                   "PEM CA to verify prometheus",
+	configPromHeaders:                 "extra prometheus headers as Name=Value, comma separated",
+	configPromPreset:                  "validator metrics to discover pubkeys from, " + strings.Join(prom.PresetNames(), ", "),
+	configPromQuery:                   "PromQL overriding the preset, %s is replaced with the selector",
+	configPromPubkeyLabel:             "label overriding the preset pubkey label",
+	configPromNetworkLabel:            "label overriding the preset network label",
+	configPromNetwork:                 "network to discover validators of, * for every network",
+	configBeaconEndpoint:              "beaconcha.in explorer to query",
+}
+
+// switches can be passed as a bare --flag
+var switches = map[string]bool{
+	configResume: true,
+}
+
+// flag sets shared between commands
+var (
+	sourceFlags = []string{configSource, configFile, configBeaconEndpoint, configPromEndpoint, configPromUser, configPromPassword,
+		configPromBearerToken, configPromTLSCert, configPromTLSKey, configPromTLSCA, configPromHeaders,
+		configPromPreset, configPromQuery, configPromPubkeyLabel, configPromNetworkLabel, configPromNetwork}
+	ruleFlags  = []string{configTimeRange, configAttestationEffectivenessMin, configAttestationEfficiencyMax}
+	shardFlags = []string{configShardIndex, configShardCount}
+	scanFlags  = []string{configOutFile, configInfoFile, configIncidentsFile, configErrorsFile, configLabelColumns, configBenchmarkFile, configBenchmarkThreshold,
+		configDetailsFile, configEvidenceFile}
+	reportFlags = []string{configCorrelationFile, configCorrelationLabels, configCorrelationMinValidators,
+		configSnapshotDir, configStoreFile, configMarkdownFile, configMarkdownTop, configHTMLDir, configSummaryFile, configSummaryBy,
+		configSLAFile, configSLAWindows,
+		configPushgatewayEndpoint, configPushgatewayJob, configRemoteWriteEndpoint}
 )
 
+type command struct {
+	name  string
+	args  string
+	short string
+	flags [][]string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "scan", short: "check every validator and write the reports", flags: [][]string{sourceFlags, shardFlags, ruleFlags, scanFlags, reportFlags, {configCheckpointFile, configResume, configProgressInterval}}, run: scanCommand},
+	{name: "serve", short: "scan on a loop and serve the latest results over HTTP", flags: [][]string{sourceFlags, shardFlags, ruleFlags, scanFlags, reportFlags, {configServeAddress, configServeInterval}}, run: serveCommand},
+	{name: "estimate", short: "estimate how long a scan will take", flags: [][]string{sourceFlags, shardFlags}, run: estimateCommand},
+	{name: "merge", args: "<snapshot>...", short: "combine the snapshots of sharded scans into a single report", flags: [][]string{{configOutFile, configInfoFile, configIncidentsFile, configLabelColumns}, reportFlags}, run: mergeCommand},
+	{name: "inspect", args: "<pubkey>", short: "check a single validator and print its health", flags: [][]string{{configBeaconEndpoint}, ruleFlags}, run: inspectCommand},
+	{name: "summary", args: "[snapshot]", short: "summarize a snapshot per group, default the latest", flags: [][]string{{configSnapshotDir, configSummaryFile, configSummaryBy}}, run: summaryCommand},
+	{name: "sla", args: "[snapshot]", short: "report duty rates per month or window from a snapshot, default the latest", flags: [][]string{{configSnapshotDir, configSLAFile, configSLAWindows}}, run: slaCommand},
+	{name: "diff", args: "[from] [to]", short: "report the changes between two snapshots, default the two latest", flags: [][]string{{configSnapshotDir, configDiffFile}}, run: diffCommand},
+	{name: "history", args: "<pubkey>", short: "print every recorded scan of a validator as csv", flags: [][]string{{configStoreFile}}, run: historyCommand},
+	{name: "slashed", short: "print the validators recorded as slashed as csv", flags: [][]string{{configStoreFile, configHistorySince, configHistoryUntil}}, run: slashedCommand},
+	{name: "issues", short: "list the issue types with their alert thresholds", flags: [][]string{{configAlertThreshold}}, run: issuesCommand},
+	{name: "export", short: "write prometheus alert rules and a grafana dashboard", flags: [][]string{{configRulesFile, configDashboardFile, configAlertThreshold}}, run: exportCommand},
+}
+
+// shardFileDefaults are the csv outputs of a scan that get the shard index in their default name, see getShardFile
+var shardFileDefaults = map[string]string{
+	configOutFile:       "./out.csv",
+	configInfoFile:      "./info.csv",
+	configIncidentsFile: "./incidents.csv",
+	configErrorsFile:    "./errors.csv",
+}
+
 func init() {
-	viper.SetDefault(configMode, "prom") // or "prom"
+	viper.SetDefault(configMode, "prom")
+	viper.SetDefault(configSource, "prom")
 	viper.SetDefault(configFile, "./pubkeys.yml")
-	viper.SetDefault(configOutFile, "./out.csv")
-	viper.SetDefault(configInfoFile, "./info.csv")
+	for key, file := range shardFileDefaults {
+		viper.SetDefault(key, file)
+	}
 	viper.SetDefault(configTimeRange, time.Hour*24*90)
+	viper.SetDefault(configPromPreset, "prysm")
+	viper.SetDefault(configSnapshotDir, "./snapshots")
+	viper.SetDefault(configProgressInterval, 30*time.Second)
+	viper.SetDefault(configShardIndex, 0)
+	viper.SetDefault(configShardCount, 1)
+	viper.SetDefault(configDiffFile, "./diff.csv")
+	viper.SetDefault(configStoreFile, "./history.db")
+	viper.SetDefault(configMarkdownTop, 10)
+	viper.SetDefault(configSummaryBy, "group")
+	viper.SetDefault(configSLAWindows, "months")
+	viper.SetDefault(configServeAddress, ":8080")
+	viper.SetDefault(configServeInterval, 6*time.Hour)
+	viper.SetDefault(configRulesFile, "./validator-health.rules.yml")
+	viper.SetDefault(configDashboardFile, "./validator-health.dashboard.json")
+	viper.SetDefault(configPushgatewayJob, "validator-health")
+	viper.SetDefault(configBenchmarkThreshold, 10.0)
+	viper.SetDefault(configLabelColumns, "instance,job")
+	viper.SetDefault(configCorrelationLabels, "instance,job")
+	viper.SetDefault(configCorrelationMinValidators, 2)
+	viper.SetDefault(configAttestationEffectivenessMin, validator.DefaultRules.MinAttestationEffectiveness)
+	viper.SetDefault(configAttestationEfficiencyMax, validator.DefaultRules.MaxAttestationEfficiency)
 }
 
 func main() {
-	processStart := time.Now()
-
-	viper.AutomaticEnv()
-	file, err := os.ReadFile(viper.GetString(configFile))
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	var promClient *prom.Client
-	var pubkeys []string
-	switch viper.GetString(configMode) {
-	case "file":
-		if viper.GetString(configFile) == "" {
-			log.Fatal("missing file config")
-		}
-		if err := yaml.Unmarshal(file, &pubkeys); err != nil {
+	args := os.Args[1:]
+	if len(args) == 0 {
+		// keep running a prom scan without a command, as before commands, for existing cron jobs
+		if err := viper.BindEnv(configMode); err != nil {
 			log.Fatal(err)
 		}
-	case "prom":
-		if viper.GetString(configPromEndpoint) == "" || viper.GetString(configPromUser) == "" || viper.GetString(configPromPassword) == "" {
-			log.Fatal("missing prom config")
+		mode := viper.GetString(configMode)
+		log.Printf("running without a command is deprecated, use: validator-stats scan --source %s\n", mode)
+		args = []string{"scan", "--source", mode}
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		if len(args) > 1 {
+			if cmd, ok := findCommand(args[1]); ok {
+				cmd.flagSet().Usage()
+				return
+			}
 		}
-		promClient, err = prom.New(
-			prom.WithAddress(viper.GetString(configPromEndpoint)),
-			prom.WithBasicAuth(viper.GetString(configPromUser), viper.GetString(configPromPassword)),
-		)
-		if err != nil {
-			log.Fatal(err)
+		usage()
+		return
+	}
+
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		usage()
+		os.Exit(2)
+	}
+	// flags are built before reading the env so their help shows the defaults rather than secrets
+	fs := cmd.flagSet()
+	if err := fs.Parse(args[1:]); err != nil {
+		if err == pflag.ErrHelp {
+			return
 		}
-		pubkeys, err = promClient.GetValidatorPubkeys(context.Background())
-		if err != nil {
-			log.Fatal(err)
+		os.Exit(2)
+	}
+	viper.AutomaticEnv()
+	if settings := viper.GetString(configSettings); settings != "" {
+		viper.SetConfigFile(settings)
+		if err := viper.ReadInConfig(); err != nil {
+			log.Fatal(errors.Wrap(err, "failed to read config"))
 		}
 	}
-	log.Printf("there are %d validators to check\n", len(pubkeys))
-
-	beaconClient := beacon.NewClient(http.DefaultClient, "", 0, 0)
-
-	client := validator.NewClient(beaconClient, promClient)
-	if err := run(client, pubkeys, processStart); err != nil {
+	if err := cmd.run(fs.Args()); err != nil {
+		var partial *partialError
+		if errors.As(err, &partial) {
+			log.Println(err)
+			os.Exit(exitPartial)
+		}
 		log.Fatal(err)
 	}
 }
 
-func run(client *validator.Client, pubkeys []string, processStart time.Time) error {
-	start := time.Now()
-	log.Printf("retrieving pubkeys took %s\n", time.Since(processStart))
-
-	client.GetEstimatedDuration(len(pubkeys))
-
-	err := writeValidators(client, pubkeys)
-	log.Printf("write took %s\n", time.Since(start))
-	return err
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
 }
 
-func writeValidators(client *validator.Client, pubkeys []string) error {
-	// manage outfile
-	outFile, err := os.Create(viper.GetString(configOutFile))
-	if err != nil {
-		return errors.Wrap(err, "failed to create out file")
+// flagSet binds a --flag for each config key of the command, flags take precedence over env vars
+func (c command) flagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet(c.name, pflag.ContinueOnError)
+	seen := make(map[string]bool)
+	for _, keys := range append([][]string{{configSettings}}, c.flags...) {
+		for _, key := range keys {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			name := strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+			fs.String(name, viper.GetString(key), fmt.Sprintf("%s (env %s)", usages[key], key))
+			if switches[key] {
+				fs.Lookup(name).NoOptDefVal = "true"
+			}
+			if err := viper.BindPFlag(key, fs.Lookup(name)); err != nil {
+				log.Fatal(err)
+			}
+		}
 	}
-	defer outFile.Close()
-	outWriter := csv.NewWriter(outFile)
-	defer outWriter.Flush()
-
-	// write headers
-	err = outWriter.Write([]string{"pubkey", "issue_type", "count", "timestamp", "status", "withdrawal_credentials"})
-	if err != nil {
-		return err
+	fs.SortFlags = false
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n\nUsage:\n  validator-stats %s [flags] %s\n\nFlags:\n%s", c.short, c.name, c.args, fs.FlagUsages())
 	}
-	outWriter.Flush()
+	return fs
+}
 
-	// manage info file
-	infoFile, err := os.Create(viper.GetString(configInfoFile))
-	if err != nil {
-		return errors.Wrap(err, "failed to create info file")
+func usage() {
+	var names []string
+	width := 0
+	for _, cmd := range commands {
+		names = append(names, cmd.name)
+		if len(cmd.name) > width {
+			width = len(cmd.name)
+		}
 	}
-	defer infoFile.Close()
-	infoWriter := csv.NewWriter(infoFile)
-	defer infoWriter.Flush()
-	err = infoWriter.Write([]string{"pubkey", "status", "withdrawal", "slashed", "name", "index", "timestamp"})
-	if err != nil {
-		return err
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "Checks the health of validators using beaconcha.in\n\nUsage:\n  validator-stats <command> [flags]\n\nCommands:\n")
+	for _, name := range names {
+		cmd, _ := findCommand(name)
+		fmt.Fprintf(os.Stderr, "  %-*s  %s\n", width, cmd.name, cmd.short)
 	}
-	infoWriter.Flush()
-
-	// make a request and immediately write to file
-	lookback := -viper.GetDuration(configTimeRange)
-	for _, pubkey := range pubkeys {
-		var lines [][]string
-		health, err := client.GetValidatorHealth(pubkey, lookback)
-		if err != nil {
-			log.Printf("skipping %s\n", pubkey)
-			continue
-		}
-		info := health.Info.Data
+	fmt.Fprintf(os.Stderr, "\nRun 'validator-stats help <command>' for the flags of a command.\n")
+}
 
-		err = infoWriter.Write([]string{info.Pubkey, info.Status, info.Withdrawalcredentials, strconv.FormatBool(info.Slashed), info.Name, strconv.Itoa(info.Validatorindex), time.Now().String()})
-		if err != nil {
-			return err
-		}
-		infoWriter.Flush()
-
-		// write health conditions file
-		for _, conditions := range health.Conditions {
-			for _, condition := range conditions {
-				lines = append(lines, []string{
-					health.Info.Data.Pubkey,
-					string(condition.IssueType),
-					fmt.Sprintf("%d",
-						condition.Count,
-					), condition.Day.String(),
-					health.Info.Data.Status,
-					health.Info.Data.Withdrawalcredentials,
-				})
-			}
-			if err := outWriter.WriteAll(lines); err != nil {
-				return errors.Wrap(err, "error writing record to file")
-			}
+// newValidatorClient builds the client shared by the commands that query beaconcha.in, promClient may be nil
+func newValidatorClient(promClient *prom.Client) *validator.Client {
+	beaconClient := beacon.NewClient(http.DefaultClient, viper.GetString(configBeaconEndpoint), 0, 0)
+	return validator.NewClient(beaconClient, promClient, validator.WithRules(validator.Rules{
+		MinAttestationEffectiveness: viper.GetFloat64(configAttestationEffectivenessMin),
+		MaxAttestationEfficiency:    viper.GetFloat64(configAttestationEfficiencyMax),
+	}))
+}
 
-		}
+// getDuration reads a duration config value, unlike viper.GetDuration a value that doesn't parse is an error rather than 0
+func getDuration(key string) (time.Duration, error) {
+	d, err := time.ParseDuration(viper.GetString(key))
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid %s", key)
+	}
+	return d, nil
+}
 
+// getList reads a comma separated config value
+func getList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(viper.GetString(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
 	}
-	return nil
+	return list
 }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/0xste/validator-stats/internal/snapshot"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// mergeCommand combines the snapshots of sharded scans into one, writing out.csv, info.csv and the reports as if a
+// single scan had checked every validator. The merged snapshot is taken at the latest of the shards and saved as
+// a .merged snapshot
+func mergeCommand(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("merge takes at least 2 snapshots, got %d", len(args))
+	}
+	merged := &snapshot.Snapshot{Merged: true}
+	seen := make(map[string]bool)
+	for _, path := range args {
+		shard, err := snapshot.Load(path)
+		if err != nil {
+			return err
+		}
+		if shard.Taken.After(merged.Taken) {
+			merged.Taken = shard.Taken
+		}
+		if shard.Lookback > merged.Lookback {
+			merged.Lookback = shard.Lookback
+		}
+		for _, health := range shard.Healths {
+			pubkey := health.Info.Data.Pubkey
+			if seen[pubkey] {
+				log.Printf("%s is in more than one shard, keeping the first\n", pubkey)
+				continue
+			}
+			seen[pubkey] = true
+			merged.Healths = append(merged.Healths, health)
+		}
+	}
+	log.Printf("merged %d validators from %d shards\n", len(merged.Healths), len(args))
+
+	if err := writeMerged(merged); err != nil {
+		return err
+	}
+	groups, err := getGroups()
+	if err != nil {
+		return err
+	}
+	return writeReports(groups, merged)
+}
+
+// writeMerged writes out.csv, incidents.csv and info.csv from the results of a merged snapshot
+func writeMerged(merged *snapshot.Snapshot) error {
+	labels := getList(configLabelColumns)
+
+	outFile, outWriter, err := openCSV(viper.GetString(configOutFile), false,
+		outHeader(labels))
+	if err != nil {
+		return errors.Wrap(err, "failed to create out file")
+	}
+	defer outFile.Close()
+	defer outWriter.Flush()
+
+	infoFile, infoWriter, err := openCSV(viper.GetString(configInfoFile), false,
+		infoHeader(labels))
+	if err != nil {
+		return errors.Wrap(err, "failed to create info file")
+	}
+	defer infoFile.Close()
+	defer infoWriter.Flush()
+
+	incidentsFile, incidentsWriter, err := openCSV(viper.GetString(configIncidentsFile), false,
+		incidentsHeader(labels))
+	if err != nil {
+		return errors.Wrap(err, "failed to create incidents file")
+	}
+	defer incidentsFile.Close()
+	defer incidentsWriter.Flush()
+
+	for _, health := range merged.Healths {
+		if err := infoWriter.Write(infoRow(health, labels, merged.Taken)); err != nil {
+			return errors.Wrap(err, "error writing record to file")
+		}
+		if err := outWriter.WriteAll(outRows(health, labels)); err != nil {
+			return errors.Wrap(err, "error writing record to file")
+		}
+		if err := incidentsWriter.WriteAll(incidentsRows(health, labels)); err != nil {
+			return errors.Wrap(err, "error writing record to file")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,610 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/aggregate"
+	"github.com/0xste/validator-stats/internal/checkpoint"
+	"github.com/0xste/validator-stats/internal/report"
+	"github.com/0xste/validator-stats/internal/snapshot"
+	"github.com/0xste/validator-stats/internal/store"
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/0xste/validator-stats/pkg/beacon"
+	"github.com/0xste/validator-stats/pkg/prom"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+func scanCommand(args []string) error {
+	processStart := time.Now()
+	// checked up front rather than finding out hours into the scan
+	if _, err := getDuration(configProgressInterval); err != nil {
+		return err
+	}
+	groups, err := getGroups()
+	if err != nil {
+		return err
+	}
+	promClient, err := getSourcePromClient(groups)
+	if err != nil {
+		return err
+	}
+	targets, err := getTargets(groups)
+	if err != nil {
+		return err
+	}
+	_, err = run(newValidatorClient(promClient), groups, targets, processStart)
+	return err
+}
+
+func run(client *validator.Client, groups []group, targets []validator.Target, processStart time.Time) ([]*validator.Health, error) {
+	start := time.Now()
+	log.Printf("retrieving pubkeys took %s\n", time.Since(processStart))
+
+	client.GetEstimatedDuration(len(targets))
+
+	pubkeys := make([]string, 0, len(targets))
+	for _, target := range targets {
+		pubkeys = append(pubkeys, target.Pubkey)
+	}
+	if err := client.PrefetchAttestationPerformance(pubkeys); err != nil {
+		log.Printf("failed to prefetch attestation performance: %s\n", err)
+	}
+
+	healths, failed, err := writeValidators(client, groups, targets)
+	log.Printf("write took %s\n", time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+
+	current := &snapshot.Snapshot{
+		Taken:    start,
+		Lookback: viper.GetDuration(configTimeRange),
+		Healths:  healths,
+	}
+	if count := viper.GetInt(configShardCount); count > 1 {
+		current.Shard, current.Shards = viper.GetInt(configShardIndex), count
+	}
+	if err := writeReports(groups, current); err != nil {
+		return nil, err
+	}
+	if failed > 0 {
+		return healths, &partialError{failed: failed, total: len(targets)}
+	}
+	return healths, nil
+}
+
+// exitPartial is the exit code of a scan that wrote its reports without some validators
+const exitPartial = 3
+
+// partialError is returned by a scan that couldn't check every validator, the reports leave them out
+type partialError struct {
+	failed, total int
+}
+
+func (e *partialError) Error() string {
+	return fmt.Sprintf("%d of %d validators couldn't be checked, see %s", e.failed, e.total, getShardFile(configErrorsFile))
+}
+
+// writeReports saves the snapshot of a scan and writes everything derived from its results
+func writeReports(groups []group, current *snapshot.Snapshot) error {
+	healths, start := current.Healths, current.Taken
+	paths, err := snapshot.List(viper.GetString(configSnapshotDir))
+	if err != nil {
+		return err
+	}
+	// changes are against the previous snapshot of the same kind, a merged scan isn't compared with a single shard
+	// and a shard only with itself
+	previous := snapshot.OfKind(paths, current.Kind())
+	path, err := snapshot.Save(viper.GetString(configSnapshotDir), current)
+	if err != nil {
+		return err
+	}
+	log.Printf("saved snapshot %s\n", path)
+
+	if file := viper.GetString(configMarkdownFile); file != "" {
+		var changes []snapshot.Change
+		if len(previous) > 0 {
+			last, err := snapshot.Load(previous[len(previous)-1])
+			if err != nil {
+				return err
+			}
+			changes = snapshot.Diff(last, current)
+			if changes == nil {
+				changes = []snapshot.Change{}
+			}
+		}
+		if err := writeMarkdown(file, current, changes); err != nil {
+			return err
+		}
+	}
+
+	if file := viper.GetString(configSummaryFile); file != "" {
+		summaries := aggregate.Summarize(healths, viper.GetString(configSummaryBy), start.Add(-current.Lookback))
+		if err := writeSummary(file, summaries); err != nil {
+			return err
+		}
+	}
+
+	if file := viper.GetString(configSLAFile); file != "" {
+		windows, err := getWindows(start, current.Lookback)
+		if err != nil {
+			return err
+		}
+		if err := writeSLA(file, slaRows(healths, windows)); err != nil {
+			return err
+		}
+	}
+
+	if dir := viper.GetString(configHTMLDir); dir != "" {
+		if err := report.WriteHTML(dir, start, current.Lookback, healths); err != nil {
+			return err
+		}
+		log.Printf("wrote html report to %s\n", dir)
+	}
+
+	history, err := store.Open(viper.GetString(configStoreFile))
+	if err != nil {
+		return err
+	}
+	defer history.Close()
+	if err := history.Record(start, healths); err != nil {
+		return errors.Wrap(err, "failed to record history")
+	}
+
+	if file := viper.GetString(configCorrelationFile); file != "" {
+		if err := writeCorrelations(file, healths); err != nil {
+			return err
+		}
+	}
+	notifyGroups(groups, start, healths)
+	return pushMetrics(healths)
+}
+
+func writeMarkdown(path string, current *snapshot.Snapshot, changes []snapshot.Change) error {
+	markdownFile, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to create markdown file")
+	}
+	defer markdownFile.Close()
+	return report.WriteMarkdown(markdownFile, current.Taken, current.Healths, changes, viper.GetInt(configMarkdownTop))
+}
+
+// pushMetrics sends the per validator gauges to a Pushgateway and/or remote-write endpoint when configured
+func pushMetrics(healths []*validator.Health) error {
+	pushgateway, remoteWrite := viper.GetString(configPushgatewayEndpoint), viper.GetString(configRemoteWriteEndpoint)
+	if pushgateway == "" && remoteWrite == "" {
+		return nil
+	}
+	metrics, err := validator.Metrics(healths, getList(configLabelColumns))
+	if err != nil {
+		return errors.Wrap(err, "failed to build metrics")
+	}
+	if pushgateway != "" {
+		pusher, err := prom.New(append(getPromAuth(), prom.WithAddress(pushgateway))...)
+		if err != nil {
+			return err
+		}
+		if err := pusher.Push(context.Background(), viper.GetString(configPushgatewayJob), metrics); err != nil {
+			return errors.Wrap(err, "failed to push to pushgateway")
+		}
+		log.Printf("pushed metrics for %d validators to %s\n", len(healths), pushgateway)
+	}
+	if remoteWrite != "" {
+		writer, err := prom.New(append(getPromAuth(), prom.WithAddress(remoteWrite))...)
+		if err != nil {
+			return err
+		}
+		if err := writer.RemoteWrite(context.Background(), metrics); err != nil {
+			return errors.Wrap(err, "failed to remote write")
+		}
+		log.Printf("remote wrote metrics for %d validators to %s\n", len(healths), remoteWrite)
+	}
+	return nil
+}
+
+func writeCorrelations(path string, healths []*validator.Health) error {
+	labels := getList(configCorrelationLabels)
+	outages := validator.Correlate(healths, labels, viper.GetInt(configCorrelationMinValidators))
+	log.Printf("found %d correlated outages\n", len(outages))
+
+	correlationFile, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to create correlation file")
+	}
+	defer correlationFile.Close()
+	correlationWriter := csv.NewWriter(correlationFile)
+	defer correlationWriter.Flush()
+
+	header := []string{"issue_type", "day", "epoch", "validators", "count", "cause", "groups"}
+	header = append(header, labels...)
+	if err := correlationWriter.Write(header); err != nil {
+		return err
+	}
+	for _, outage := range outages {
+		var epoch string
+		if outage.Epoch != nil {
+			epoch = strconv.Itoa(*outage.Epoch)
+		}
+		line := []string{
+			string(outage.IssueType),
+			outage.Day,
+			epoch,
+			strconv.Itoa(len(outage.Validators)),
+			strconv.Itoa(outage.Count),
+			outage.Cause(),
+			strings.Join(outage.Groups, ";"),
+		}
+		for _, label := range labels {
+			line = append(line, outage.Labels[label])
+		}
+		if err := correlationWriter.Write(line); err != nil {
+			return errors.Wrap(err, "error writing record to file")
+		}
+	}
+	return nil
+}
+
+// writeValidators checks the targets and writes their rows, failed is the number written to ERRORS_FILE instead
+func writeValidators(client *validator.Client, groups []group, targets []validator.Target) ([]*validator.Health, int, error) {
+	labels := getList(configLabelColumns)
+	resume := viper.GetBool(configResume)
+
+	// completed validators are skipped and appended to the existing outputs on resume
+	progress, entries, err := checkpoint.Open(getCheckpointFile(), resume)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to open checkpoint")
+	}
+	defer progress.Close()
+	// keyed by the target pubkey, which is what the loop below skips on
+	completed := make(map[string]bool, len(entries))
+	healths := make([]*validator.Health, 0, len(targets))
+	for _, entry := range entries {
+		completed[entry.Pubkey] = true
+		healths = append(healths, entry.Health)
+	}
+	// without a checkpoint there is nothing to resume and the outputs start over
+	resume = resume && len(entries) > 0
+	if resume {
+		log.Printf("resuming with %d of %d validators completed\n", len(completed), len(targets))
+	}
+
+	// manage outfile
+	outFile, outWriter, err := openCSV(getShardFile(configOutFile), resume,
+		outHeader(labels))
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to create out file")
+	}
+	defer outFile.Close()
+	defer outWriter.Flush()
+
+	// manage incidents file
+	incidentsFile, incidentsWriter, err := openCSV(getShardFile(configIncidentsFile), resume,
+		incidentsHeader(labels))
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to create incidents file")
+	}
+	defer incidentsFile.Close()
+	defer incidentsWriter.Flush()
+
+	// failed validators aren't checkpointed so the file starts over, --resume retries them
+	errorsFile, errorsWriter, err := openCSV(getShardFile(configErrorsFile), false,
+		[]string{"pubkey", "reason", "error", "timestamp", "group"})
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to create errors file")
+	}
+	defer errorsFile.Close()
+	defer errorsWriter.Flush()
+
+	// manage info file
+	infoFile, infoWriter, err := openCSV(getShardFile(configInfoFile), resume,
+		infoHeader(labels))
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to create info file")
+	}
+	defer infoFile.Close()
+	defer infoWriter.Flush()
+
+	// manage benchmark file
+	var benchmarkWriter *csv.Writer
+	if viper.GetString(configBenchmarkFile) != "" {
+		benchmarkFile, w, err := openCSV(viper.GetString(configBenchmarkFile), resume,
+			[]string{"pubkey", "days", "apr", "ethstore_apr", "gap_pct", "underperforming", "group"})
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to create benchmark file")
+		}
+		defer benchmarkFile.Close()
+		benchmarkWriter = w
+		defer benchmarkWriter.Flush()
+	}
+
+	// manage details file
+	var detailsWriter *csv.Writer
+	if viper.GetString(configDetailsFile) != "" {
+		detailsFile, w, err := openCSV(viper.GetString(configDetailsFile), resume,
+			[]string{"pubkey", "issue_type", "timestamp", "epoch", "slot", "group"})
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to create details file")
+		}
+		defer detailsFile.Close()
+		detailsWriter = w
+		defer detailsWriter.Flush()
+	}
+
+	// manage evidence file
+	var evidenceWriter *csv.Writer
+	if viper.GetString(configEvidenceFile) != "" {
+		evidenceFile, w, err := openCSV(viper.GetString(configEvidenceFile), resume,
+			[]string{"pubkey", "issue_type", "timestamp", "probe", "query", "samples", "first", "last", "min", "max", "local_trouble", "corroborated", "group"})
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to create evidence file")
+		}
+		defer evidenceFile.Close()
+		evidenceWriter = w
+		defer evidenceWriter.Flush()
+	}
+	probes := getProbes(groups)
+	failed := 0
+
+	stop := client.ReportProgress(len(targets)-len(completed), viper.GetDuration(configProgressInterval))
+	defer stop()
+
+	// make a request and immediately write to file
+	lookback := viper.GetDuration(configTimeRange)
+	for _, target := range targets {
+		pubkey := target.Pubkey
+		if completed[pubkey] {
+			continue
+		}
+		health, err := client.GetValidatorHealth(target, lookback)
+		if err != nil {
+			log.Printf("skipping %s: %s\n", pubkey, err)
+			failed++
+			if err := errorsWriter.Write([]string{pubkey, beacon.Reason(err), err.Error(), time.Now().String(), target.Group}); err != nil {
+				return nil, 0, errors.Wrap(err, "error writing record to file")
+			}
+			errorsWriter.Flush()
+			continue
+		}
+		healths = append(healths, health)
+
+		if err := infoWriter.Write(infoRow(health, labels, time.Now())); err != nil {
+			return nil, 0, err
+		}
+		infoWriter.Flush()
+
+		if benchmarkWriter != nil {
+			benchmark, err := client.GetBenchmark(health, lookback, viper.GetFloat64(configBenchmarkThreshold))
+			if err != nil {
+				log.Printf("skipping benchmark for %s: %s\n", pubkey, err)
+			} else {
+				err = benchmarkWriter.Write([]string{
+					benchmark.Pubkey,
+					strconv.Itoa(benchmark.Days),
+					strconv.FormatFloat(benchmark.Apr, 'f', 6, 64),
+					strconv.FormatFloat(benchmark.EthStoreApr, 'f', 6, 64),
+					strconv.FormatFloat(benchmark.GapPct, 'f', 2, 64),
+					strconv.FormatBool(benchmark.Underperforming),
+					health.Group,
+				})
+				if err != nil {
+					return nil, 0, err
+				}
+				benchmarkWriter.Flush()
+			}
+		}
+
+		if detailsWriter != nil {
+			if err := client.DrillDownAttestations(health); err != nil {
+				log.Printf("skipping drill-down for %s: %s\n", pubkey, err)
+			}
+			var details [][]string
+			for _, conditions := range health.Conditions {
+				for _, condition := range conditions {
+					for _, duty := range condition.Missed {
+						details = append(details, []string{
+							health.Info.Data.Pubkey,
+							string(condition.IssueType),
+							condition.Day.String(),
+							strconv.Itoa(duty.Epoch),
+							strconv.Itoa(duty.Slot),
+							health.Group,
+						})
+					}
+				}
+			}
+			if err := detailsWriter.WriteAll(details); err != nil {
+				return nil, 0, errors.Wrap(err, "error writing record to file")
+			}
+		}
+
+		if evidenceWriter != nil {
+			if err := client.CollectEvidence(health, probes[target.Group]); err != nil {
+				log.Printf("skipping evidence for %s: %s\n", pubkey, err)
+			}
+			var evidence [][]string
+			for _, conditions := range health.Conditions {
+				for _, condition := range conditions {
+					corroborated := ""
+					if c := condition.Corroborated(); c != nil {
+						corroborated = strconv.FormatBool(*c)
+					}
+					for _, e := range condition.Evidence {
+						evidence = append(evidence, []string{
+							health.Info.Data.Pubkey,
+							string(condition.IssueType),
+							condition.Day.String(),
+							e.Probe,
+							e.Query,
+							strconv.Itoa(e.Samples),
+							strconv.FormatFloat(e.First, 'f', -1, 64),
+							strconv.FormatFloat(e.Last, 'f', -1, 64),
+							strconv.FormatFloat(e.Min, 'f', -1, 64),
+							strconv.FormatFloat(e.Max, 'f', -1, 64),
+							strconv.FormatBool(e.Trouble),
+							corroborated,
+							health.Group,
+						})
+					}
+				}
+			}
+			if err := evidenceWriter.WriteAll(evidence); err != nil {
+				return nil, 0, errors.Wrap(err, "error writing record to file")
+			}
+		}
+
+		// write health conditions file
+		if err := outWriter.WriteAll(outRows(health, labels)); err != nil {
+			return nil, 0, errors.Wrap(err, "error writing record to file")
+		}
+		if err := incidentsWriter.WriteAll(incidentsRows(health, labels)); err != nil {
+			return nil, 0, errors.Wrap(err, "error writing record to file")
+		}
+
+		if err := progress.Record(pubkey, health); err != nil {
+			return nil, 0, errors.Wrap(err, "failed to record checkpoint")
+		}
+	}
+	// the checkpoint is kept while validators failed so --resume only retries those
+	if failed == 0 {
+		if err := progress.Remove(); err != nil {
+			log.Printf("failed to remove checkpoint: %s\n", err)
+		}
+	}
+	return healths, failed, nil
+}
+
+// getCheckpointFile defaults the checkpoint per shard so shards sharing a directory don't truncate each other's
+func getCheckpointFile() string {
+	if file := viper.GetString(configCheckpointFile); file != "" {
+		return file
+	}
+	if viper.GetInt(configShardCount) > 1 {
+		return fmt.Sprintf("./checkpoint-%d.jsonl", viper.GetInt(configShardIndex))
+	}
+	return "./checkpoint.jsonl"
+}
+
+// getShardFile adds the shard index to a csv output left at its default in a sharded scan, e.g. ./out-1.csv,
+// so shards in the same directory keep their own files like their checkpoints
+func getShardFile(key string) string {
+	file := viper.GetString(key)
+	if viper.GetInt(configShardCount) <= 1 || file != shardFileDefaults[key] {
+		return file
+	}
+	ext := filepath.Ext(file)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(file, ext), viper.GetInt(configShardIndex), ext)
+}
+
+func infoHeader(labels []string) []string {
+	return append([]string{"pubkey", "status", "withdrawal", "slashed", "name", "index", "timestamp", "attestation_effectiveness", "attestation_efficiency", "group"}, labels...)
+}
+
+func outHeader(labels []string) []string {
+	return append([]string{"pubkey", "issue_type", "count", "timestamp", "status", "withdrawal_credentials", "group"}, labels...)
+}
+
+// infoRow is the info.csv row of a validator checked at timestamp
+func infoRow(health *validator.Health, labels []string, timestamp time.Time) []string {
+	info := health.Info.Data
+	var effectiveness, efficiency *float64
+	if health.Attestation != nil {
+		effectiveness, efficiency = health.Attestation.Effectiveness, health.Attestation.Efficiency
+	}
+	return append([]string{info.Pubkey, info.Status, info.Withdrawalcredentials, strconv.FormatBool(info.Slashed), info.Name, strconv.Itoa(info.Validatorindex), timestamp.String(),
+		formatOptional(effectiveness), formatOptional(efficiency), health.Group}, labelValues(health, labels)...)
+}
+
+// outRows are the out.csv rows of a validator, one per condition
+func outRows(health *validator.Health, labels []string) [][]string {
+	var lines [][]string
+	for _, conditions := range health.Conditions {
+		for _, condition := range conditions {
+			lines = append(lines, append([]string{
+				health.Info.Data.Pubkey,
+				string(condition.IssueType),
+				fmt.Sprintf("%d",
+					condition.Count,
+				), condition.Day.String(),
+				health.Info.Data.Status,
+				health.Info.Data.Withdrawalcredentials,
+				health.Group,
+			}, labelValues(health, labels)...))
+		}
+	}
+	return lines
+}
+
+func incidentsHeader(labels []string) []string {
+	return append([]string{"pubkey", "issue_type", "start", "end", "duration", "days", "count", "peak_day", "peak_count", "status", "group"}, labels...)
+}
+
+// incidentsRows are the incidents.csv rows of a validator, oldest first
+func incidentsRows(health *validator.Health, labels []string) [][]string {
+	var lines [][]string
+	for _, incident := range health.Incidents() {
+		lines = append(lines, append([]string{
+			incident.Pubkey,
+			string(incident.IssueType),
+			incident.Start.String(),
+			incident.End.String(),
+			incident.Duration().String(),
+			strconv.Itoa(incident.Days),
+			strconv.Itoa(incident.Count),
+			incident.PeakDay.String(),
+			strconv.Itoa(incident.PeakCount),
+			health.Info.Data.Status,
+			incident.Group,
+		}, labelValues(health, labels)...))
+	}
+	return lines
+}
+
+// openCSV creates path and writes header, or on resume appends to it when it already has rows
+func openCSV(path string, resume bool, header []string) (*os.File, *csv.Writer, error) {
+	if resume {
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				return nil, nil, err
+			}
+			return file, csv.NewWriter(file), nil
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	w := csv.NewWriter(file)
+	if err := w.Write(header); err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	w.Flush()
+	return file, w, w.Error()
+}
+
+// formatOptional leaves the cell empty when beaconcha.in didn't return a value
+func formatOptional(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', 4, 64)
+}
+
+// labelValues returns the value of each label column, empty when the validator doesn't have the label
+func labelValues(health *validator.Health, labels []string) []string {
+	values := make([]string, 0, len(labels))
+	for _, label := range labels {
+		values = append(values, health.Labels[label])
+	}
+	return values
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/server"
+	"github.com/0xste/validator-stats/internal/snapshot"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// serveCommand answers queries from the latest scan results while rescanning every SERVE_INTERVAL
+func serveCommand(args []string) error {
+	groups, err := getGroups()
+	if err != nil {
+		return err
+	}
+	promClient, err := getSourcePromClient(groups)
+	if err != nil {
+		return err
+	}
+	client := newValidatorClient(promClient)
+
+	srv := server.New()
+	// answer from the previous scan until the first one here finishes, which can take hours
+	paths, err := snapshot.List(viper.GetString(configSnapshotDir))
+	if err != nil {
+		return err
+	}
+	if len(paths) > 0 {
+		latest, err := snapshot.Load(paths[len(paths)-1])
+		if err != nil {
+			return err
+		}
+		srv.Update(latest.Taken, latest.Healths)
+		log.Printf("serving %s until the first scan finishes\n", paths[len(paths)-1])
+	}
+	go func() {
+		for {
+			start := time.Now()
+			targets, err := getTargets(groups)
+			if err != nil {
+				log.Printf("failed to get pubkeys: %s\n", err)
+			} else if healths, err := run(client, groups, targets, start); err != nil && !errors.As(err, new(*partialError)) {
+				log.Printf("scan failed: %s\n", err)
+			} else {
+				// a partial scan still replaces the previous one, the missing validators are in ERRORS_FILE
+				if err != nil {
+					log.Println(err)
+				}
+				srv.Update(start, healths)
+			}
+			time.Sleep(viper.GetDuration(configServeInterval))
+		}
+	}()
+
+	log.Printf("serving on %s\n", viper.GetString(configServeAddress))
+	return http.ListenAndServe(viper.GetString(configServeAddress), srv.Handler())
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/snapshot"
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// slaRow is the SLA of a validator, or of all validators of a group, over one window
+type slaRow struct {
+	Scope              string   `json:"scope"`
+	Key                string   `json:"key"`
+	Group              string   `json:"group"`
+	Window             string   `json:"window"`
+	From               string   `json:"from"`
+	Until              string   `json:"until"`
+	Days               int      `json:"days"`
+	AttestationDuties  int      `json:"attestation_duties"`
+	MissedAttestations int      `json:"missed_attestations"`
+	AttestationRate    *float64 `json:"attestation_rate"`
+	ProposedBlocks     int      `json:"proposed_blocks"`
+	MissedBlocks       int      `json:"missed_blocks"`
+	ProposalRate       *float64 `json:"proposal_rate"`
+	ParticipatedSync   int      `json:"sync_participated"`
+	MissedSync         int      `json:"sync_missed"`
+	SyncRate           *float64 `json:"sync_rate"`
+}
+
+// slaCommand reports the SLA of a snapshot, default the latest in SNAPSHOT_DIR, to SLA_FILE or stdout
+func slaCommand(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("sla takes at most 1 snapshot, got %d", len(args))
+	}
+	var path string
+	if len(args) == 1 {
+		path = args[0]
+	} else {
+		paths, err := snapshot.List(viper.GetString(configSnapshotDir))
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			return fmt.Errorf("no snapshots in %s", viper.GetString(configSnapshotDir))
+		}
+		path = paths[len(paths)-1]
+	}
+	current, err := snapshot.Load(path)
+	if err != nil {
+		return err
+	}
+	windows, err := getWindows(current.Taken, current.Lookback)
+	if err != nil {
+		return err
+	}
+	rows := slaRows(current.Healths, windows)
+
+	file := viper.GetString(configSLAFile)
+	if file == "" {
+		return writeSLACSV(os.Stdout, rows)
+	}
+	return writeSLA(file, rows)
+}
+
+// getWindows parses SLA_WINDOWS, either months for the calendar months overlapping the time range or a list of
+// from:until dates
+func getWindows(taken time.Time, lookback time.Duration) ([]validator.Window, error) {
+	windows := getList(configSLAWindows)
+	if len(windows) == 1 && windows[0] == "months" {
+		return validator.Months(taken.Add(-lookback), taken), nil
+	}
+	var parsed []validator.Window
+	for _, window := range windows {
+		from, until, ok := strings.Cut(window, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s %q, expected months or from:until dates", configSLAWindows, window)
+		}
+		fromDay, err := time.Parse("2006-01-02", strings.TrimSpace(from))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", configSLAWindows)
+		}
+		untilDay, err := time.Parse("2006-01-02", strings.TrimSpace(until))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", configSLAWindows)
+		}
+		parsed = append(parsed, validator.DateWindow(fromDay, untilDay))
+	}
+	return parsed, nil
+}
+
+// slaRows has a row per validator and window followed by a row per group and window
+func slaRows(healths []*validator.Health, windows []validator.Window) []slaRow {
+	var rows, groupRows []slaRow
+	for _, window := range windows {
+		groups := make(map[string]*validator.SLA)
+		var names []string
+		for _, health := range healths {
+			sla := health.SLA(window)
+			rows = append(rows, newSLARow("validator", health.Info.Data.Pubkey, health.Group, sla))
+			if _, ok := groups[health.Group]; !ok {
+				groups[health.Group] = &validator.SLA{Window: window}
+				names = append(names, health.Group)
+			}
+			groups[health.Group].Add(sla)
+		}
+		for _, name := range names {
+			groupRows = append(groupRows, newSLARow("group", name, name, *groups[name]))
+		}
+	}
+	return append(rows, groupRows...)
+}
+
+func newSLARow(scope, key, group string, sla validator.SLA) slaRow {
+	return slaRow{
+		Scope:              scope,
+		Key:                key,
+		Group:              group,
+		Window:             sla.Name,
+		From:               sla.From.Format("2006-01-02"),
+		Until:              sla.Until.AddDate(0, 0, -1).Format("2006-01-02"),
+		Days:               sla.Days,
+		AttestationDuties:  sla.AttestationDuties,
+		MissedAttestations: sla.MissedAttestations,
+		AttestationRate:    sla.AttestationRate(),
+		ProposedBlocks:     sla.ProposedBlocks,
+		MissedBlocks:       sla.MissedBlocks,
+		ProposalRate:       sla.ProposalRate(),
+		ParticipatedSync:   sla.ParticipatedSync,
+		MissedSync:         sla.MissedSync,
+		SyncRate:           sla.SyncRate(),
+	}
+}
+
+// writeSLA writes JSON when path ends in .json and csv otherwise
+func writeSLA(path string, rows []slaRow) error {
+	slaFile, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to create sla file")
+	}
+	defer slaFile.Close()
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		encoder := json.NewEncoder(slaFile)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(rows)
+	} else {
+		err = writeSLACSV(slaFile, rows)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("wrote %d sla rows to %s\n", len(rows), path)
+	return nil
+}
+
+func writeSLACSV(w io.Writer, rows []slaRow) error {
+	slaWriter := csv.NewWriter(w)
+	defer slaWriter.Flush()
+	err := slaWriter.Write([]string{"scope", "key", "group", "window", "from", "until", "days",
+		"attestation_duties", "missed_attestations", "attestation_rate",
+		"proposed_blocks", "missed_blocks", "proposal_rate",
+		"sync_participated", "sync_missed", "sync_rate"})
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		err := slaWriter.Write([]string{
+			row.Scope,
+			row.Key,
+			row.Group,
+			row.Window,
+			row.From,
+			row.Until,
+			strconv.Itoa(row.Days),
+			strconv.Itoa(row.AttestationDuties),
+			strconv.Itoa(row.MissedAttestations),
+			formatOptional(row.AttestationRate),
+			strconv.Itoa(row.ProposedBlocks),
+			strconv.Itoa(row.MissedBlocks),
+			formatOptional(row.ProposalRate),
+			strconv.Itoa(row.ParticipatedSync),
+			strconv.Itoa(row.MissedSync),
+			formatOptional(row.SyncRate),
+		})
+		if err != nil {
+			return errors.Wrap(err, "error writing record to file")
+		}
+	}
+	return nil
+}
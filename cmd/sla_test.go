@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/spf13/viper"
+)
+
+func TestGetWindows(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	taken := time.Date(2026, 10, 18, 6, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		windows string
+		want    []validator.Window
+		wantErr bool
+	}{
+		{
+			name:    "months of the lookback",
+			windows: "months",
+			want: []validator.Window{
+				{Name: "2026-08", From: date(2026, 8, 1), Until: date(2026, 9, 1)},
+				{Name: "2026-09", From: date(2026, 9, 1), Until: date(2026, 10, 1)},
+				{Name: "2026-10", From: date(2026, 10, 1), Until: date(2026, 11, 1)},
+			},
+		},
+		{
+			name:    "dates include the until day",
+			windows: "2026-10-01:2026-10-07, 2026-10-08:2026-10-14",
+			want: []validator.Window{
+				{Name: "2026-10-01..2026-10-07", From: date(2026, 10, 1), Until: date(2026, 10, 8)},
+				{Name: "2026-10-08..2026-10-14", From: date(2026, 10, 8), Until: date(2026, 10, 15)},
+			},
+		},
+		{name: "missing until", windows: "2026-10-01", wantErr: true},
+		{name: "invalid from", windows: "october:2026-10-07", wantErr: true},
+		{name: "invalid until", windows: "2026-10-01:2026-10-32", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set(configSLAWindows, tt.windows)
+			defer viper.Set(configSLAWindows, "months")
+			got, err := getWindows(taken, 60*24*time.Hour)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d windows %v, want %d", len(got), got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got[i].Name != want.Name || !got[i].From.Equal(want.From) || !got[i].Until.Equal(want.Until) {
+					t.Errorf("window %d got %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/0xste/validator-stats/internal/shard"
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/0xste/validator-stats/pkg/prom"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// getSourcePromClient builds the prometheus client evidence is collected with, the groups discover their pubkeys with
+// their own client so it has no discovery. File sources only get one when PROM_ENDPOINT is set
+func getSourcePromClient(groups []group) (*prom.Client, error) {
+	endpoint := viper.GetString(configPromEndpoint)
+	if endpoint == "" {
+		for _, g := range groups {
+			if g.Source == "prom" {
+				return nil, fmt.Errorf("missing prom config")
+			}
+		}
+		if viper.GetString(configEvidenceFile) != "" {
+			log.Printf("%s is set without %s, no evidence will be collected\n", configEvidenceFile, configPromEndpoint)
+		}
+		return nil, nil
+	}
+	return prom.New(append(getPromAuth(), prom.WithAddress(endpoint))...)
+}
+
+// getProbes returns the evidence probes of each group by name, following the preset override of the group
+func getProbes(groups []group) map[string][]prom.Probe {
+	probes := make(map[string][]prom.Probe, len(groups))
+	for _, g := range groups {
+		preset := g.Prom.Preset
+		if preset == "" {
+			preset = viper.GetString(configPromPreset)
+		}
+		probes[g.Name] = prom.ProbesFor(preset)
+	}
+	return probes
+}
+
+func getPromClient(overrides discoveryOverrides) (*prom.Client, error) {
+	if viper.GetString(configPromEndpoint) == "" {
+		return nil, fmt.Errorf("missing prom config")
+	}
+	discovery, err := getDiscovery(overrides)
+	if err != nil {
+		return nil, err
+	}
+	return prom.New(append(getPromAuth(),
+		prom.WithAddress(viper.GetString(configPromEndpoint)),
+		prom.WithDiscovery(discovery),
+	)...)
+}
+
+// getPromAuth returns the auth options that are configured, prometheus is queried unauthenticated without any
+func getPromAuth() []func(c *prom.Client) {
+	var options []func(c *prom.Client)
+	if viper.GetString(configPromUser) != "" || viper.GetString(configPromPassword) != "" {
+		options = append(options, prom.WithBasicAuth(viper.GetString(configPromUser), viper.GetString(configPromPassword)))
+	}
+	if viper.GetString(configPromBearerToken) != "" {
+		options = append(options, prom.WithBearerToken(viper.GetString(configPromBearerToken)))
+	}
+	if viper.GetString(configPromTLSCert) != "" || viper.GetString(configPromTLSKey) != "" {
+		options = append(options, prom.WithClientCert(viper.GetString(configPromTLSCert), viper.GetString(configPromTLSKey)))
+	}
+	if viper.GetString(configPromTLSCA) != "" {
+		options = append(options, prom.WithCA(viper.GetString(configPromTLSCA)))
+	}
+	for _, header := range getList(configPromHeaders) {
+		name, value, _ := strings.Cut(header, "=")
+		options = append(options, prom.WithHeader(strings.TrimSpace(name), strings.TrimSpace(value)))
+	}
+	return options
+}
+
+// getDiscovery starts from the configured preset and applies any overrides, a network of "*" disables the network filter
+func getDiscovery(overrides discoveryOverrides) (prom.Discovery, error) {
+	override := func(value, key string) string {
+		if value != "" {
+			return value
+		}
+		return viper.GetString(key)
+	}
+	preset := override(overrides.Preset, configPromPreset)
+	discovery, ok := prom.Preset(preset)
+	if !ok {
+		return discovery, fmt.Errorf("unknown prom preset %q, expected one of %s", preset, strings.Join(prom.PresetNames(), ", "))
+	}
+	if query := override(overrides.Query, configPromQuery); query != "" {
+		discovery.Query = query
+	}
+	if label := override(overrides.PubkeyLabel, configPromPubkeyLabel); label != "" {
+		discovery.PubkeyLabel = label
+	}
+	if label := override(overrides.NetworkLabel, configPromNetworkLabel); label != "" {
+		discovery.NetworkLabel = label
+	}
+	if discovery.Query == "" || discovery.PubkeyLabel == "" {
+		return discovery, fmt.Errorf("prom preset %q has no per validator series to discover pubkeys from, set %s and %s", preset, configPromQuery, configPromPubkeyLabel)
+	}
+	switch network := override(overrides.Network, configPromNetwork); network {
+	case "":
+	case "*":
+		discovery.Network = ""
+	default:
+		discovery.Network = network
+	}
+	return discovery, nil
+}
+
+// getTargets reads the pubkeys of every group, a validator in more than one group is only checked in the first.
+// With SHARD_COUNT only the validators of SHARD_INDEX are returned
+func getTargets(groups []group) ([]validator.Target, error) {
+	var targets []validator.Target
+	seen := make(map[string]string)
+	for _, g := range groups {
+		groupTargets, err := getGroupTargets(g)
+		if err != nil {
+			if g.Name != "" {
+				return nil, errors.Wrapf(err, "group %s", g.Name)
+			}
+			return nil, err
+		}
+		policy := g.policy()
+		for _, target := range groupTargets {
+			if first, ok := seen[target.Pubkey]; ok {
+				log.Printf("%s is in groups %s and %s, only checking it in %s\n", target.Pubkey, first, g.Name, first)
+				continue
+			}
+			seen[target.Pubkey] = g.Name
+			target.Group = g.Name
+			target.Policy = policy
+			targets = append(targets, target)
+		}
+	}
+	if count := viper.GetInt(configShardCount); count > 1 {
+		index := viper.GetInt(configShardIndex)
+		if index < 0 || index >= count {
+			return nil, fmt.Errorf("%s must be from 0 to %d, got %d", configShardIndex, count-1, index)
+		}
+		all := targets
+		targets = nil
+		for _, target := range all {
+			if shard.Of(target.Pubkey, count) == index {
+				targets = append(targets, target)
+			}
+		}
+		log.Printf("shard %d of %d has %d of %d validators\n", index, count, len(targets), len(all))
+	}
+	log.Printf("there are %d validators to check\n", len(targets))
+	return targets, nil
+}
+
+// getGroupTargets reads the pubkeys of a group from its file and config or discovers them in prometheus
+func getGroupTargets(g group) ([]validator.Target, error) {
+	var targets []validator.Target
+	switch g.Source {
+	case "file":
+		pubkeys := g.Pubkeys
+		if g.PubkeysFile != "" {
+			file, err := os.ReadFile(g.PubkeysFile)
+			if err != nil {
+				return nil, err
+			}
+			var filePubkeys []string
+			if err := yaml.Unmarshal(file, &filePubkeys); err != nil {
+				return nil, err
+			}
+			pubkeys = append(pubkeys, filePubkeys...)
+		}
+		if len(pubkeys) == 0 {
+			return nil, fmt.Errorf("missing file config")
+		}
+		for _, pubkey := range pubkeys {
+			targets = append(targets, validator.Target{Pubkey: pubkey})
+		}
+	case "prom":
+		promClient, err := getPromClient(g.Prom)
+		if err != nil {
+			return nil, err
+		}
+		series, err := promClient.GetValidators(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range series {
+			targets = append(targets, validator.Target{Pubkey: s.Pubkey, Labels: s.Labels})
+		}
+	default:
+		return nil, fmt.Errorf("unknown pubkey source %q", g.Source)
+	}
+	return targets, nil
+}
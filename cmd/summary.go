@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/0xste/validator-stats/internal/aggregate"
+	"github.com/0xste/validator-stats/internal/snapshot"
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// summaryCommand aggregates a snapshot, default the latest in SNAPSHOT_DIR, to SUMMARY_FILE or stdout
+func summaryCommand(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("summary takes at most 1 snapshot, got %d", len(args))
+	}
+	var path string
+	if len(args) == 1 {
+		path = args[0]
+	} else {
+		paths, err := snapshot.List(viper.GetString(configSnapshotDir))
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			return fmt.Errorf("no snapshots in %s", viper.GetString(configSnapshotDir))
+		}
+		path = paths[len(paths)-1]
+	}
+	current, err := snapshot.Load(path)
+	if err != nil {
+		return err
+	}
+	summaries := aggregate.Summarize(current.Healths, viper.GetString(configSummaryBy), current.Taken.Add(-current.Lookback))
+
+	file := viper.GetString(configSummaryFile)
+	if file == "" {
+		return writeSummaryCSV(os.Stdout, summaries)
+	}
+	return writeSummary(file, summaries)
+}
+
+// writeSummary writes JSON when path ends in .json and csv otherwise
+func writeSummary(path string, summaries []aggregate.Summary) error {
+	summaryFile, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to create summary file")
+	}
+	defer summaryFile.Close()
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		encoder := json.NewEncoder(summaryFile)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(summaries)
+	} else {
+		err = writeSummaryCSV(summaryFile, summaries)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("wrote %d summaries to %s\n", len(summaries), path)
+	return nil
+}
+
+// writeSummaryCSV flattens the status, issue type and score counts into one column each, every row has all columns
+func writeSummaryCSV(w io.Writer, summaries []aggregate.Summary) error {
+	statuses := make(map[string]bool)
+	issueTypes := make(map[validator.IssueType]bool)
+	for _, issueType := range validator.IssueTypes {
+		issueTypes[issueType] = true
+	}
+	for _, summary := range summaries {
+		for status := range summary.Statuses {
+			statuses[status] = true
+		}
+		for issueType := range summary.Issues {
+			issueTypes[issueType] = true
+		}
+	}
+	statusColumns := make([]string, 0, len(statuses))
+	for status := range statuses {
+		statusColumns = append(statusColumns, status)
+	}
+	sort.Strings(statusColumns)
+	issueColumns := make([]string, 0, len(issueTypes))
+	for issueType := range issueTypes {
+		issueColumns = append(issueColumns, string(issueType))
+	}
+	sort.Strings(issueColumns)
+
+	summaryWriter := csv.NewWriter(w)
+	defer summaryWriter.Flush()
+	header := []string{viper.GetString(configSummaryBy), "validators"}
+	for _, status := range statusColumns {
+		header = append(header, "status_"+status)
+	}
+	for _, issueType := range issueColumns {
+		header = append(header, "issue_"+issueType)
+	}
+	header = append(header, "uptime_pct", "income_gwei")
+	for _, band := range validator.ScoreBands {
+		header = append(header, "score_"+band.Name)
+	}
+	if err := summaryWriter.Write(header); err != nil {
+		return err
+	}
+
+	for _, summary := range summaries {
+		line := []string{summary.Key, strconv.Itoa(summary.Validators)}
+		for _, status := range statusColumns {
+			line = append(line, strconv.Itoa(summary.Statuses[status]))
+		}
+		for _, issueType := range issueColumns {
+			line = append(line, strconv.Itoa(summary.Issues[validator.IssueType(issueType)]))
+		}
+		uptime := ""
+		if summary.UptimePct != nil {
+			uptime = strconv.FormatFloat(*summary.UptimePct, 'f', 4, 64)
+		}
+		line = append(line, uptime, strconv.FormatInt(summary.IncomeGwei, 10))
+		for _, band := range validator.ScoreBands {
+			line = append(line, strconv.Itoa(summary.Scores[band.Name]))
+		}
+		if err := summaryWriter.Write(line); err != nil {
+			return errors.Wrap(err, "error writing record to file")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package aggregate
+
+import (
+	"sort"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/validator"
+)
+
+// Summary is the aggregate of the validators sharing a group or label value
+type Summary struct {
+	// Key is the group name or label value the validators share, empty for validators without one
+	Key        string         `json:"key"`
+	Validators int            `json:"validators"`
+	Statuses   map[string]int `json:"statuses"`
+	// Issues are the summed counts of daily conditions, e.g. the missed duties, and the number of validators
+	// with each other issue type
+	Issues map[validator.IssueType]int `json:"issues"`
+	// UptimePct is the share of attestation duties fulfilled by all the validators, nil without stats
+	UptimePct  *float64       `json:"uptime_pct"`
+	IncomeGwei int64          `json:"income_gwei"`
+	Scores     map[string]int `json:"scores"`
+
+	duties, missedDuties int
+}
+
+// Summarize aggregates healths by group, or by the value of a label when by is anything else, over the days
+// ending after since
+func Summarize(healths []*validator.Health, by string, since time.Time) []Summary {
+	summaries := make(map[string]*Summary)
+	var keys []string
+	for _, health := range healths {
+		key := health.Group
+		if by != "group" {
+			key = health.Labels[by]
+		}
+		summary, ok := summaries[key]
+		if !ok {
+			summary = &Summary{
+				Key:      key,
+				Statuses: make(map[string]int),
+				Issues:   make(map[validator.IssueType]int),
+				Scores:   make(map[string]int),
+			}
+			for _, band := range validator.ScoreBands {
+				summary.Scores[band.Name] = 0
+			}
+			summaries[key] = summary
+			keys = append(keys, key)
+		}
+
+		summary.Validators++
+		summary.Statuses[health.Info.Data.Status]++
+		for _, condition := range health.Conditions[health.Info.Data.Pubkey] {
+			summary.Issues[condition.IssueType] += condition.Occurrences()
+		}
+		duties, missed := health.AttestationDuties(since)
+		summary.duties += duties
+		summary.missedDuties += missed
+		summary.IncomeGwei += int64(health.Income(since))
+		summary.Scores[validator.ScoreBand(health.Score(since))]++
+	}
+
+	sort.Strings(keys)
+	result := make([]Summary, 0, len(keys))
+	for _, key := range keys {
+		summary := summaries[key]
+		if summary.duties > 0 {
+			uptime := float64(summary.duties-summary.missedDuties) / float64(summary.duties) * 100
+			summary.UptimePct = &uptime
+		}
+		result = append(result, *summary)
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package checkpoint
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/0xste/validator-stats/internal/validator"
+)
+
+// Checkpoint records the result of every validator as it completes, one JSON line each, so a scan that dies can
+// resume where it stopped
+type Checkpoint struct {
+	path string
+	file *os.File
+}
+
+// Entry is a completed validator
+type Entry struct {
+	// Pubkey is the pubkey of the target as it was discovered, which can be an index or differ in case and 0x
+	// prefix from the pubkey beaconcha.in returns
+	Pubkey string
+	Health *validator.Health
+}
+
+// Open starts a new checkpoint at path, or with resume continues an existing one and returns the validators it
+// already completed. A line left half written by a crash is dropped
+func Open(path string, resume bool) (*Checkpoint, []Entry, error) {
+	var completed []Entry
+	if resume {
+		var err error
+		if completed, err = read(path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// rewrite the valid lines so a half written one isn't followed by the next record
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := &Checkpoint{path: path, file: file}
+	for _, entry := range completed {
+		if err := c.Record(entry.Pubkey, entry.Health); err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+	}
+	return c, completed, nil
+}
+
+func read(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var completed []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Health == nil {
+			break
+		}
+		completed = append(completed, entry)
+	}
+	return completed, scanner.Err()
+}
+
+// Record appends a completed validator under the pubkey of its target and syncs it to disk
+func (c *Checkpoint) Record(pubkey string, health *validator.Health) error {
+	line, err := json.Marshal(Entry{Pubkey: pubkey, Health: health})
+	if err != nil {
+		return err
+	}
+	if _, err := c.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return c.file.Sync()
+}
+
+func (c *Checkpoint) Close() error {
+	return c.file.Close()
+}
+
+// Remove deletes the checkpoint once the scan completed
+func (c *Checkpoint) Remove() error {
+	c.file.Close()
+	return os.Remove(c.path)
+}
@@ -0,0 +1,112 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+func testHealth(pubkey string) *validator.Health {
+	return &validator.Health{Info: beacon.Validator{Data: beacon.ValidatorData{Pubkey: pubkey, Status: "active_online"}}}
+}
+
+func TestResume(t *testing.T) {
+	tests := []struct {
+		name string
+		// tail is appended to a checkpoint of 0xaa and 0xbb as if the scan died while writing it
+		tail string
+		want []string
+	}{
+		{name: "complete", want: []string{"0xaa", "0xbb"}},
+		{name: "truncated last line", tail: `{"Pubkey":"0xcc","Health":{"Info":{"da`, want: []string{"0xaa", "0xbb"}},
+		{name: "line without a health", tail: `{"Pubkey":"0xcc"}` + "\n", want: []string{"0xaa", "0xbb"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+			c, _, err := Open(path, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, pubkey := range []string{"0xaa", "0xbb"} {
+				if err := c.Record(pubkey, testHealth(pubkey)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c.Close()
+			file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			file.WriteString(tt.tail)
+			file.Close()
+
+			c, completed, err := Open(path, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(completed) != len(tt.want) {
+				t.Fatalf("got %d completed, want %d", len(completed), len(tt.want))
+			}
+			for i, pubkey := range tt.want {
+				if completed[i].Pubkey != pubkey || completed[i].Health.Info.Data.Pubkey != pubkey {
+					t.Errorf("entry %d got %s, want %s", i, completed[i].Pubkey, pubkey)
+				}
+			}
+
+			// the next record follows the valid lines rather than the half written one
+			if err := c.Record("0xdd", testHealth("0xdd")); err != nil {
+				t.Fatal(err)
+			}
+			c.Close()
+			_, completed, err = Open(path, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := len(completed); n != len(tt.want)+1 || completed[n-1].Pubkey != "0xdd" {
+				t.Errorf("got %d completed after resuming, want %d ending with 0xdd", n, len(tt.want)+1)
+			}
+		})
+	}
+}
+
+func TestOpenWithoutResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+	c, _, err := Open(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Record("0xaa", testHealth("0xaa"))
+	c.Close()
+
+	c, completed, err := Open(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	if len(completed) != 0 {
+		t.Errorf("got %d completed without resume, want 0", len(completed))
+	}
+	c, completed, err = Open(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if len(completed) != 0 {
+		t.Errorf("got %d completed after a fresh start, want 0", len(completed))
+	}
+}
+
+func TestResumeMissingFile(t *testing.T) {
+	c, completed, err := Open(filepath.Join(t.TempDir(), "checkpoint.jsonl"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if len(completed) != 0 {
+		t.Errorf("got %d completed, want 0", len(completed))
+	}
+}
@@ -0,0 +1,135 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/0xste/validator-stats/internal/validator"
+)
+
+type panel struct {
+	ID          int            `json:"id"`
+	Type        string         `json:"type"`
+	Title       string         `json:"title"`
+	GridPos     gridPos        `json:"gridPos"`
+	Datasource  datasource     `json:"datasource"`
+	Targets     []target       `json:"targets"`
+	FieldConfig map[string]any `json:"fieldConfig,omitempty"`
+}
+
+type gridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type datasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type target struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+	Instant      bool   `json:"instant,omitempty"`
+	Format       string `json:"format,omitempty"`
+}
+
+var promDatasource = datasource{Type: "prometheus", UID: "${datasource}"}
+
+// Dashboard renders a grafana dashboard over the exported metrics, the per issue panels are coloured at the same
+// thresholds as the alert rules
+func Dashboard(severities map[validator.IssueType]Severity) ([]byte, error) {
+	if err := checkSeverities(severities); err != nil {
+		return nil, err
+	}
+	var panels []panel
+	add := func(kind, title string, w, h int, fieldConfig map[string]any, targets ...target) {
+		// lay panels out left to right, wrapping at grafana's 24 columns
+		x, y := 0, 0
+		if n := len(panels); n > 0 {
+			last := panels[n-1].GridPos
+			x, y = last.X+last.W, last.Y
+			if x+w > 24 {
+				x, y = 0, last.Y+last.H
+			}
+		}
+		panels = append(panels, panel{
+			ID:          len(panels) + 1,
+			Type:        kind,
+			Title:       title,
+			GridPos:     gridPos{H: h, W: w, X: x, Y: y},
+			Datasource:  promDatasource,
+			Targets:     targets,
+			FieldConfig: fieldConfig,
+		})
+	}
+
+	add("stat", "Validators", 6, 4, nil, target{RefID: "A", Expr: fmt.Sprintf("count(%s)", validator.MetricStatus)})
+	add("stat", "Not active_online", 6, 4, thresholds(1, 1), target{RefID: "A", Expr: fmt.Sprintf(`count(%s{status!="active_online"}) or vector(0)`, validator.MetricStatus)})
+	add("stat", "Slashed", 6, 4, thresholds(0, 1), target{RefID: "A", Expr: fmt.Sprintf("sum(%s)", validator.MetricSlashed)})
+	add("stat", "Since last run", 6, 4, map[string]any{"defaults": map[string]any{"unit": "s"}}, target{RefID: "A", Expr: fmt.Sprintf("time() - max(%s)", validator.MetricLastRun)})
+
+	for _, issueType := range validator.IssueTypes {
+		severity := severities[issueType]
+		add("stat", string(issueType), 4, 4, thresholds(severity.Warning, severity.Critical), target{
+			RefID: "A",
+			Expr:  fmt.Sprintf(`max(%s{issue_type=%q}) or vector(0)`, validator.MetricConditions, issueType),
+		})
+	}
+
+	add("timeseries", "Conditions by issue type", 12, 8, nil, target{
+		RefID:        "A",
+		Expr:         fmt.Sprintf("sum by (issue_type) (%s)", validator.MetricConditions),
+		LegendFormat: "{{issue_type}}",
+	})
+	add("timeseries", "Attestation effectiveness", 12, 8, nil, target{
+		RefID:        "A",
+		Expr:         fmt.Sprintf("avg(%s)", validator.MetricAttestationEffective),
+		LegendFormat: "average",
+	}, target{
+		RefID:        "B",
+		Expr:         fmt.Sprintf("min(%s)", validator.MetricAttestationEffective),
+		LegendFormat: "worst",
+	})
+	add("table", "Worst validators", 24, 10, nil, target{
+		RefID:   "A",
+		Expr:    fmt.Sprintf("topk(20, sum by (pubkey) (%s))", validator.MetricConditions),
+		Instant: true,
+		Format:  "table",
+	})
+
+	return json.MarshalIndent(map[string]any{
+		"title":         "Validator Health",
+		"uid":           "validator-health",
+		"schemaVersion": 39,
+		"tags":          []string{"validator-health"},
+		"time":          map[string]string{"from": "now-30d", "to": "now"},
+		"templating": map[string]any{
+			"list": []map[string]any{{
+				"name":  "datasource",
+				"type":  "datasource",
+				"query": "prometheus",
+			}},
+		},
+		"panels": panels,
+	}, "", "  ")
+}
+
+// thresholds colours a panel yellow at warning and red at critical, 0 leaves that step out
+func thresholds(warning, critical int) map[string]any {
+	steps := []map[string]any{{"color": "green", "value": nil}}
+	if warning > 0 {
+		steps = append(steps, map[string]any{"color": "yellow", "value": warning})
+	}
+	if critical > 0 {
+		steps = append(steps, map[string]any{"color": "red", "value": critical})
+	}
+	return map[string]any{
+		"defaults": map[string]any{
+			"thresholds": map[string]any{"mode": "absolute", "steps": steps},
+		},
+	}
+}
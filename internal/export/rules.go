@@ -0,0 +1,115 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0xste/validator-stats/internal/validator"
+	"gopkg.in/yaml.v2"
+)
+
+// Severity holds the condition counts at which an issue alerts, 0 disables that severity
+type Severity = validator.Severity
+
+// DefaultSeverities are the severities of the issue catalogue
+var DefaultSeverities = func() map[validator.IssueType]Severity {
+	severities := make(map[validator.IssueType]Severity)
+	for _, issue := range validator.Issues {
+		if !issue.Status {
+			severities[issue.Type] = issue.Severity
+		}
+	}
+	return severities
+}()
+
+type ruleFile struct {
+	Groups []ruleGroup `yaml:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []rule `yaml:"rules"`
+}
+
+type rule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// Rules renders a prometheus alerting rule file with an alert per issue type, one rule for each enabled severity.
+// A severity for an issue type outside the catalogue is an error as its rule would never fire
+func Rules(severities map[validator.IssueType]Severity) ([]byte, error) {
+	if err := checkSeverities(severities); err != nil {
+		return nil, err
+	}
+	group := ruleGroup{Name: "validator-health"}
+	for _, issueType := range validator.IssueTypes {
+		severity := severities[issueType]
+		for _, level := range []struct {
+			name      string
+			threshold int
+		}{{"warning", severity.Warning}, {"critical", severity.Critical}} {
+			if level.threshold <= 0 {
+				continue
+			}
+			group.Rules = append(group.Rules, rule{
+				Alert:  alertName(issueType),
+				Expr:   fmt.Sprintf(`%s{issue_type=%q} >= %d`, validator.MetricConditions, issueType, level.threshold),
+				Labels: map[string]string{"severity": level.name},
+				Annotations: map[string]string{
+					"summary":     fmt.Sprintf("validator {{ $labels.pubkey }} has %s", issueType),
+					"description": fmt.Sprintf("{{ $value }} %s within the time range, the %s threshold is %d", issueType, level.name, level.threshold),
+				},
+			})
+		}
+	}
+
+	group.Rules = append(group.Rules,
+		rule{
+			Alert:  "ValidatorNotActiveOnline",
+			Expr:   fmt.Sprintf(`%s{status!="active_online"} == 1`, validator.MetricStatus),
+			Labels: map[string]string{"severity": "critical"},
+			Annotations: map[string]string{
+				"summary": "validator {{ $labels.pubkey }} is {{ $labels.status }}",
+			},
+		},
+		rule{
+			Alert: "ValidatorHealthStale",
+			// remote-written samples go stale after 5 minutes, the last one within a week still says when the last run was
+			Expr:   fmt.Sprintf(`time() - last_over_time(%s[7d]) > 2 * 86400`, validator.MetricLastRun),
+			For:    "1h",
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary": "validator health results haven't been pushed for 2 days",
+			},
+		},
+	)
+
+	return yaml.Marshal(ruleFile{Groups: []ruleGroup{group}})
+}
+
+// checkSeverities rejects issue types that aren't in the catalogue or alert through the status metric
+func checkSeverities(severities map[validator.IssueType]Severity) error {
+	for issueType := range severities {
+		if issue, ok := validator.LookupIssue(issueType); !ok || issue.Status {
+			return fmt.Errorf("unknown issue type %s, see validator-stats issues", issueType)
+		}
+	}
+	return nil
+}
+
+// alertName turns missed_attestation into ValidatorMissedAttestation
+func alertName(issueType validator.IssueType) string {
+	var sb strings.Builder
+	sb.WriteString("Validator")
+	for _, word := range strings.Split(string(issueType), "_") {
+		if word == "" {
+			continue
+		}
+		sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return sb.String()
+}
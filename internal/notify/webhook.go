@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/validator"
+)
+
+// Summary is posted to the notification targets of a group after every scan
+type Summary struct {
+	Group      string                      `json:"group"`
+	Taken      time.Time                   `json:"taken"`
+	Validators int                         `json:"validators"`
+	Statuses   map[string]int              `json:"statuses"`
+	Conditions map[validator.IssueType]int `json:"conditions"`
+	// Unhealthy are the pubkeys of the validators with any condition
+	Unhealthy []string `json:"unhealthy"`
+}
+
+// Summarize counts the results of the validators in group
+func Summarize(group string, taken time.Time, healths []*validator.Health) Summary {
+	summary := Summary{
+		Group:      group,
+		Taken:      taken,
+		Statuses:   make(map[string]int),
+		Conditions: make(map[validator.IssueType]int),
+		Unhealthy:  []string{},
+	}
+	for _, health := range healths {
+		if health.Group != group {
+			continue
+		}
+		summary.Validators++
+		summary.Statuses[health.Info.Data.Status]++
+		conditions := health.Conditions[health.Info.Data.Pubkey]
+		for _, condition := range conditions {
+			summary.Conditions[condition.IssueType] += condition.Occurrences()
+		}
+		if len(conditions) > 0 {
+			summary.Unhealthy = append(summary.Unhealthy, health.Info.Data.Pubkey)
+		}
+	}
+	return summary
+}
+
+// Webhook posts summary as JSON to url
+func Webhook(ctx context.Context, url string, summary Summary) error {
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook response was %d", resp.StatusCode)
+	}
+	return nil
+}
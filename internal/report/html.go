@@ -0,0 +1,238 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/pkg/errors"
+)
+
+//go:embed templates/*.html
+var templates embed.FS
+
+var htmlTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"gwei": func(gwei int64) string { return fmt.Sprintf("%.4f", float64(gwei)/1e9) },
+	"day":  func(t time.Time) string { return t.UTC().Format(dayLayout) },
+}).ParseFS(templates, "templates/*.html"))
+
+const (
+	dayLayout   = "2006-01-02"
+	chartWidth  = 720
+	chartHeight = 160
+)
+
+type fleetPage struct {
+	Taken       time.Time
+	Validators  []validatorRow
+	ByStatus    []count
+	ByIssueType []count
+}
+
+type validatorRow struct {
+	Pubkey     string
+	Page       string
+	Index      int
+	Group      string
+	Status     string
+	Slashed    bool
+	Conditions int
+}
+
+type count struct {
+	Name  string
+	Count int
+}
+
+type validatorPage struct {
+	Taken    time.Time
+	Health   *validator.Health
+	Calendar [][]calendarDay
+	Chart    *balanceChart
+	Issues   []count
+}
+
+type calendarDay struct {
+	Day   time.Time
+	Count int
+	// Level picks the heatmap colour, -1 is outside the time range
+	Level int
+}
+
+type balanceChart struct {
+	Width, Height int
+	Points        string
+	Min, Max      int64
+	First, Last   time.Time
+}
+
+// WriteHTML writes a fleet summary to dir/index.html and a page per validator to dir/validators
+func WriteHTML(dir string, taken time.Time, lookback time.Duration, healths []*validator.Health) error {
+	if err := os.MkdirAll(filepath.Join(dir, "validators"), 0o755); err != nil {
+		return errors.Wrap(err, "failed to create html dir")
+	}
+
+	fleet := fleetPage{Taken: taken}
+	statuses := make(map[string]int)
+	issueTypes := make(map[string]int)
+	for _, health := range healths {
+		info := health.Info.Data
+		conditions := health.Conditions[info.Pubkey]
+		row := validatorRow{
+			Pubkey:     info.Pubkey,
+			Page:       "validators/" + info.Pubkey + ".html",
+			Index:      info.Validatorindex,
+			Group:      health.Group,
+			Status:     info.Status,
+			Slashed:    info.Slashed,
+			Conditions: len(conditions),
+		}
+		fleet.Validators = append(fleet.Validators, row)
+		statuses[info.Status]++
+		issues := make(map[string]int)
+		for _, condition := range conditions {
+			issueTypes[string(condition.IssueType)] += condition.Occurrences()
+			issues[string(condition.IssueType)] += condition.Occurrences()
+		}
+
+		page := validatorPage{
+			Taken:    taken,
+			Health:   health,
+			Calendar: calendar(health, taken, lookback),
+			Chart:    chart(health, taken, lookback),
+			Issues:   counts(issues),
+		}
+		if err := render(filepath.Join(dir, row.Page), "validator.html", page); err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(fleet.Validators, func(i, j int) bool {
+		return fleet.Validators[i].Conditions > fleet.Validators[j].Conditions
+	})
+	fleet.ByStatus = counts(statuses)
+	fleet.ByIssueType = counts(issueTypes)
+	return render(filepath.Join(dir, "index.html"), "index.html", fleet)
+}
+
+func render(path, name string, data any) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", path)
+	}
+	defer file.Close()
+	if err := htmlTemplates.ExecuteTemplate(file, name, data); err != nil {
+		return errors.Wrapf(err, "failed to render %s", path)
+	}
+	return nil
+}
+
+// counts sorts a tally by count, largest first
+func counts(m map[string]int) []count {
+	var sorted []count
+	for name, n := range m {
+		sorted = append(sorted, count{Name: name, Count: n})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count == sorted[j].Count {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Count > sorted[j].Count
+	})
+	return sorted
+}
+
+// calendar lays the daily conditions of the time range out in weeks starting on monday
+func calendar(health *validator.Health, taken time.Time, lookback time.Duration) [][]calendarDay {
+	perDay := make(map[string]int)
+	for _, condition := range health.Conditions[health.Info.Data.Pubkey] {
+		if condition.IssueType.Daily() {
+			perDay[condition.Day.UTC().Format(dayLayout)] += condition.Occurrences()
+		}
+	}
+
+	end := truncateDay(taken)
+	start := truncateDay(taken.Add(-lookback))
+	start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+
+	var weeks [][]calendarDay
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 7) {
+		week := make([]calendarDay, 7)
+		for i := range week {
+			d := day.AddDate(0, 0, i)
+			n := perDay[d.Format(dayLayout)]
+			week[i] = calendarDay{Day: d, Count: n, Level: level(n)}
+			if d.Before(truncateDay(taken.Add(-lookback))) || d.After(end) {
+				week[i].Level = -1
+			}
+		}
+		weeks = append(weeks, week)
+	}
+	return weeks
+}
+
+func level(n int) int {
+	switch {
+	case n == 0:
+		return 0
+	case n < 5:
+		return 1
+	case n < 20:
+		return 2
+	case n < 100:
+		return 3
+	}
+	return 4
+}
+
+// chart plots the end of day balance over the time range as an svg polyline
+func chart(health *validator.Health, taken time.Time, lookback time.Duration) *balanceChart {
+	if health.Stats == nil {
+		return nil
+	}
+	stats := append(health.Stats.Data[:0:0], health.Stats.Data...)
+	sort.Slice(stats, func(i, j int) bool { return stats[i].DayEnd.Before(stats[j].DayEnd) })
+	threshold := taken.Add(-lookback)
+	var days []time.Time
+	var balances []int64
+	for _, stat := range stats {
+		if stat.DayEnd.After(threshold) {
+			days = append(days, stat.DayEnd)
+			balances = append(balances, int64(stat.EndBalance))
+		}
+	}
+	if len(balances) < 2 {
+		return nil
+	}
+
+	c := &balanceChart{Width: chartWidth, Height: chartHeight, Min: balances[0], Max: balances[0], First: days[0], Last: days[len(days)-1]}
+	for _, balance := range balances {
+		if balance < c.Min {
+			c.Min = balance
+		}
+		if balance > c.Max {
+			c.Max = balance
+		}
+	}
+	spread := float64(c.Max - c.Min)
+	if spread == 0 {
+		spread = 1
+	}
+	points := make([]string, 0, len(balances))
+	for i, balance := range balances {
+		x := float64(i) / float64(len(balances)-1) * chartWidth
+		y := chartHeight - float64(balance-c.Min)/spread*chartHeight
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	c.Points = strings.Join(points, " ")
+	return c
+}
+
+func truncateDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/snapshot"
+	"github.com/0xste/validator-stats/internal/validator"
+)
+
+// WriteMarkdown summarises a run for pasting into tickets, changes are since the previous run and
+// are left out when there was none
+func WriteMarkdown(w io.Writer, taken time.Time, healths []*validator.Health, changes []snapshot.Change, top int) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Validator health %s\n\n", taken.UTC().Format(dayLayout))
+
+	issueTypes := make(map[string]int)
+	statuses := make(map[string]int)
+	var worst []*validator.Health
+	for _, health := range healths {
+		statuses[health.Info.Data.Status]++
+		conditions := health.Conditions[health.Info.Data.Pubkey]
+		for _, condition := range conditions {
+			issueTypes[string(condition.IssueType)] += condition.Occurrences()
+		}
+		if len(conditions) > 0 {
+			worst = append(worst, health)
+		}
+	}
+	sort.SliceStable(worst, func(i, j int) bool {
+		return total(worst[i]) > total(worst[j])
+	})
+	fmt.Fprintf(&sb, "%d validators checked, %d with conditions.\n\n", len(healths), len(worst))
+
+	sb.WriteString("## Status\n\n| Status | Validators |\n| --- | ---: |\n")
+	for _, c := range counts(statuses) {
+		fmt.Fprintf(&sb, "| %s | %d |\n", c.Name, c.Count)
+	}
+
+	sb.WriteString("\n## Issues\n\n")
+	if len(issueTypes) == 0 {
+		sb.WriteString("No conditions.\n")
+	} else {
+		sb.WriteString("| Issue type | Count |\n| --- | ---: |\n")
+		for _, c := range counts(issueTypes) {
+			fmt.Fprintf(&sb, "| %s | %d |\n", c.Name, c.Count)
+		}
+	}
+
+	if changes != nil {
+		sb.WriteString("\n## New since the last run\n\n")
+		var n int
+		for _, change := range changes {
+			switch change.Kind {
+			case snapshot.NewCondition:
+				fmt.Fprintf(&sb, "- `%s` %s %s\n", change.Pubkey, change.To, change.Detail)
+			case snapshot.StatusChange, snapshot.CredentialsChange:
+				fmt.Fprintf(&sb, "- `%s` %s %s -> %s\n", change.Pubkey, change.Kind, change.From, change.To)
+			case snapshot.NewlySlashed:
+				fmt.Fprintf(&sb, "- `%s` **slashed**\n", change.Pubkey)
+			default:
+				continue
+			}
+			n++
+		}
+		if n == 0 {
+			sb.WriteString("Nothing new.\n")
+		}
+	}
+
+	// a negative top lists no validators rather than slicing out of range
+	if top < 0 {
+		top = 0
+	}
+	topN := worst
+	if len(topN) > top {
+		topN = topN[:top]
+	}
+	fmt.Fprintf(&sb, "\n## Top %d validators\n\n", len(topN))
+	if len(topN) > 0 {
+		sb.WriteString("| Pubkey | Index | Group | Status | Conditions |\n| --- | ---: | --- | --- | ---: |\n")
+		for _, health := range topN {
+			info := health.Info.Data
+			fmt.Fprintf(&sb, "| `%s` | %d | %s | %s | %d |\n", info.Pubkey, info.Validatorindex, health.Group, info.Status, total(health))
+		}
+	}
+
+	sb.WriteString("\n## Details\n\n")
+	for _, health := range worst {
+		info := health.Info.Data
+		fmt.Fprintf(&sb, "<details>\n<summary><code>%s</code> %s, %d conditions</summary>\n\n", info.Pubkey, info.Status, total(health))
+		sb.WriteString("| Day | Issue type | Count |\n| --- | --- | ---: |\n")
+		conditions := append([]validator.Condition(nil), health.Conditions[info.Pubkey]...)
+		sort.SliceStable(conditions, func(i, j int) bool {
+			return conditions[i].Day.After(conditions[j].Day)
+		})
+		for _, condition := range conditions {
+			fmt.Fprintf(&sb, "| %s | %s | %d |\n", condition.Day.UTC().Format(dayLayout), condition.IssueType, condition.Count)
+		}
+		sb.WriteString("\n</details>\n\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func total(health *validator.Health) int {
+	var n int
+	for _, condition := range health.Conditions[health.Info.Data.Pubkey] {
+		n += condition.Occurrences()
+	}
+	return n
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/validator"
+)
+
+//go:embed openapi.json
+var openAPI []byte
+
+// Server answers queries from the latest scan results
+type Server struct {
+	mu      sync.RWMutex
+	taken   time.Time
+	healths []*validator.Health
+	byKey   map[string]*validator.Health
+}
+
+func New() *Server {
+	return &Server{byKey: make(map[string]*validator.Health)}
+}
+
+// Update replaces the results being served
+func (s *Server) Update(taken time.Time, healths []*validator.Health) {
+	byKey := make(map[string]*validator.Health, len(healths))
+	for _, health := range healths {
+		byKey[health.Info.Data.Pubkey] = health
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taken = taken
+	s.healths = healths
+	s.byKey = byKey
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+	mux.HandleFunc("/validators", s.getValidators)
+	mux.HandleFunc("/validators/", s.getValidatorHealth)
+	mux.HandleFunc("/conditions", s.getConditions)
+	mux.HandleFunc("/summary", s.getSummary)
+	return mux
+}
+
+type validatorView struct {
+	Pubkey                string            `json:"pubkey"`
+	Index                 int               `json:"index"`
+	Status                string            `json:"status"`
+	Slashed               bool              `json:"slashed"`
+	WithdrawalCredentials string            `json:"withdrawal_credentials"`
+	Group                 string            `json:"group,omitempty"`
+	Labels                map[string]string `json:"labels,omitempty"`
+	Conditions            int               `json:"conditions"`
+}
+
+type conditionView struct {
+	Pubkey    string    `json:"pubkey"`
+	Group     string    `json:"group,omitempty"`
+	IssueType string    `json:"issue_type"`
+	Count     int       `json:"count"`
+	Day       time.Time `json:"day"`
+}
+
+type healthView struct {
+	validatorView
+	Balance                  int64           `json:"balance"`
+	AttestationEffectiveness *float64        `json:"attestation_effectiveness"`
+	AttestationEfficiency    *float64        `json:"attestation_efficiency"`
+	ConditionList            []conditionView `json:"condition_list"`
+}
+
+type summaryView struct {
+	Taken       time.Time      `json:"taken"`
+	Validators  int            `json:"validators"`
+	ByStatus    map[string]int `json:"by_status"`
+	ByIssueType map[string]int `json:"by_issue_type"`
+}
+
+// getValidators serves /validators?group=
+func (s *Server) getValidators(w http.ResponseWriter, r *http.Request) {
+	group := r.URL.Query().Get("group")
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	views := make([]validatorView, 0, len(s.healths))
+	for _, health := range s.healths {
+		if group != "" && health.Group != group {
+			continue
+		}
+		views = append(views, viewOf(health))
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+// getValidatorHealth serves /validators/{pubkey}/health
+func (s *Server) getValidatorHealth(w http.ResponseWriter, r *http.Request) {
+	pubkey, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/validators/"), "/health")
+	if !ok || pubkey == "" || strings.Contains(pubkey, "/") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	health, ok := s.byKey[pubkey]
+	if !ok {
+		writeError(w, http.StatusNotFound, "validator not found in the latest scan")
+		return
+	}
+	view := healthView{
+		validatorView: viewOf(health),
+		Balance:       health.Info.Data.Balance,
+		ConditionList: conditionsOf(health, "", time.Time{}),
+	}
+	if health.Attestation != nil {
+		view.AttestationEffectiveness = health.Attestation.Effectiveness
+		view.AttestationEfficiency = health.Attestation.Efficiency
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+// getConditions serves /conditions?issue_type=&since=&group=, since is RFC3339 or a date
+func (s *Server) getConditions(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			if since, err = time.Parse("2006-01-02", value); err != nil {
+				writeError(w, http.StatusBadRequest, "since must be RFC3339 or YYYY-MM-DD")
+				return
+			}
+		}
+	}
+	issueType := r.URL.Query().Get("issue_type")
+	group := r.URL.Query().Get("group")
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	conditions := make([]conditionView, 0)
+	for _, health := range s.healths {
+		if group != "" && health.Group != group {
+			continue
+		}
+		conditions = append(conditions, conditionsOf(health, issueType, since)...)
+	}
+	sort.SliceStable(conditions, func(i, j int) bool {
+		return conditions[i].Day.After(conditions[j].Day)
+	})
+	writeJSON(w, http.StatusOK, conditions)
+}
+
+func (s *Server) getSummary(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	summary := summaryView{
+		Taken:       s.taken,
+		Validators:  len(s.healths),
+		ByStatus:    make(map[string]int),
+		ByIssueType: make(map[string]int),
+	}
+	for _, health := range s.healths {
+		summary.ByStatus[health.Info.Data.Status]++
+		for _, condition := range health.Conditions[health.Info.Data.Pubkey] {
+			summary.ByIssueType[string(condition.IssueType)] += condition.Occurrences()
+		}
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func viewOf(health *validator.Health) validatorView {
+	info := health.Info.Data
+	return validatorView{
+		Pubkey:                info.Pubkey,
+		Index:                 info.Validatorindex,
+		Status:                info.Status,
+		Slashed:               info.Slashed,
+		WithdrawalCredentials: info.Withdrawalcredentials,
+		Group:                 health.Group,
+		Labels:                health.Labels,
+		Conditions:            len(health.Conditions[info.Pubkey]),
+	}
+}
+
+func conditionsOf(health *validator.Health, issueType string, since time.Time) []conditionView {
+	var views []conditionView
+	for _, condition := range health.Conditions[health.Info.Data.Pubkey] {
+		if issueType != "" && string(condition.IssueType) != issueType {
+			continue
+		}
+		if condition.Day.Before(since) {
+			continue
+		}
+		views = append(views, conditionView{
+			Pubkey:    health.Info.Data.Pubkey,
+			Group:     health.Group,
+			IssueType: string(condition.IssueType),
+			Count:     condition.Count,
+			Day:       condition.Day,
+		})
+	}
+	return views
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+func testServer() *Server {
+	day := func(n int) time.Time {
+		return time.Date(2026, 10, n, 12, 0, 23, 0, time.UTC)
+	}
+	health := func(pubkey, status, group string, conditions ...validator.Condition) *validator.Health {
+		return &validator.Health{
+			Info:       beacon.Validator{Data: beacon.ValidatorData{Pubkey: pubkey, Status: status, Balance: 32000000000}},
+			Conditions: map[string][]validator.Condition{pubkey: conditions},
+			Group:      group,
+		}
+	}
+	s := New()
+	s.Update(time.Date(2026, 10, 18, 6, 0, 0, 0, time.UTC), []*validator.Health{
+		health("0xaa", "active_online", "client-a",
+			validator.Condition{Day: day(10), Count: 3, IssueType: "missed_attestation"},
+			validator.Condition{Day: day(16), Count: 1, IssueType: "missed_block"},
+		),
+		health("0xbb", "active_exiting", "client-b",
+			validator.Condition{Day: day(17), Count: 180000, IssueType: "exit_epoch"},
+			validator.Condition{Day: day(12), Count: 2, IssueType: "missed_attestation"},
+		),
+	})
+	return s
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		// want is compared with the response decoded into a value of the same type
+		want any
+	}{
+		{
+			name:       "validators",
+			path:       "/validators",
+			wantStatus: http.StatusOK,
+			want: []validatorView{
+				{Pubkey: "0xaa", Status: "active_online", Group: "client-a", Conditions: 2},
+				{Pubkey: "0xbb", Status: "active_exiting", Group: "client-b", Conditions: 2},
+			},
+		},
+		{
+			name:       "validators of a group",
+			path:       "/validators?group=client-b",
+			wantStatus: http.StatusOK,
+			want:       []validatorView{{Pubkey: "0xbb", Status: "active_exiting", Group: "client-b", Conditions: 2}},
+		},
+		{
+			name:       "validators of an unknown group",
+			path:       "/validators?group=client-c",
+			wantStatus: http.StatusOK,
+			want:       []validatorView{},
+		},
+		{
+			name:       "health",
+			path:       "/validators/0xaa/health",
+			wantStatus: http.StatusOK,
+			want: healthView{
+				validatorView: validatorView{Pubkey: "0xaa", Status: "active_online", Group: "client-a", Conditions: 2},
+				Balance:       32000000000,
+				ConditionList: []conditionView{
+					{Pubkey: "0xaa", Group: "client-a", IssueType: "missed_attestation", Count: 3, Day: time.Date(2026, 10, 10, 12, 0, 23, 0, time.UTC)},
+					{Pubkey: "0xaa", Group: "client-a", IssueType: "missed_block", Count: 1, Day: time.Date(2026, 10, 16, 12, 0, 23, 0, time.UTC)},
+				},
+			},
+		},
+		{name: "health of an unknown validator", path: "/validators/0xcc/health", wantStatus: http.StatusNotFound},
+		{name: "health without the suffix", path: "/validators/0xaa", wantStatus: http.StatusNotFound},
+		{name: "health of a nested path", path: "/validators/0xaa/x/health", wantStatus: http.StatusNotFound},
+		{
+			name:       "conditions newest first",
+			path:       "/conditions?issue_type=missed_attestation",
+			wantStatus: http.StatusOK,
+			want: []conditionView{
+				{Pubkey: "0xbb", Group: "client-b", IssueType: "missed_attestation", Count: 2, Day: time.Date(2026, 10, 12, 12, 0, 23, 0, time.UTC)},
+				{Pubkey: "0xaa", Group: "client-a", IssueType: "missed_attestation", Count: 3, Day: time.Date(2026, 10, 10, 12, 0, 23, 0, time.UTC)},
+			},
+		},
+		{
+			name:       "conditions since a date of a group",
+			path:       "/conditions?since=2026-10-13&group=client-a",
+			wantStatus: http.StatusOK,
+			want: []conditionView{
+				{Pubkey: "0xaa", Group: "client-a", IssueType: "missed_block", Count: 1, Day: time.Date(2026, 10, 16, 12, 0, 23, 0, time.UTC)},
+			},
+		},
+		{
+			name:       "conditions since RFC3339",
+			path:       "/conditions?since=2026-10-17T00:00:00Z",
+			wantStatus: http.StatusOK,
+			want: []conditionView{
+				{Pubkey: "0xbb", Group: "client-b", IssueType: "exit_epoch", Count: 180000, Day: time.Date(2026, 10, 17, 12, 0, 23, 0, time.UTC)},
+			},
+		},
+		{name: "conditions with an invalid since", path: "/conditions?since=yesterday", wantStatus: http.StatusBadRequest},
+		{
+			name:       "summary counts conditions that aren't daily once",
+			path:       "/summary",
+			wantStatus: http.StatusOK,
+			want: summaryView{
+				Taken:       time.Date(2026, 10, 18, 6, 0, 0, 0, time.UTC),
+				Validators:  2,
+				ByStatus:    map[string]int{"active_online": 1, "active_exiting": 1},
+				ByIssueType: map[string]int{"missed_attestation": 5, "missed_block": 1, "exit_epoch": 1},
+			},
+		},
+	}
+	handler := testServer().Handler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("got Content-Type %q, want application/json", got)
+			}
+			if tt.want == nil {
+				return
+			}
+			got := reflect.New(reflect.TypeOf(tt.want))
+			if err := json.Unmarshal(recorder.Body.Bytes(), got.Interface()); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Elem().Interface(), tt.want) {
+				t.Errorf("got %+v\nwant %+v", got.Elem().Interface(), tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateReplacesResults(t *testing.T) {
+	s := testServer()
+	s.Update(time.Now(), nil)
+	recorder := httptest.NewRecorder()
+	s.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/validators/0xaa/health", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("got status %d for a validator of the previous scan, want 404", recorder.Code)
+	}
+}
@@ -0,0 +1,28 @@
+package shard
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+// Of assigns a pubkey to one of count shards with jump consistent hashing, so changing the count only moves
+// the pubkeys that have to move
+func Of(pubkey string, count int) int {
+	if count <= 1 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToLower(pubkey)))
+	return jump(h.Sum64(), count)
+}
+
+// jump is the jump consistent hash of Lamping and Veach, https://arxiv.org/abs/1406.2294
+func jump(key uint64, buckets int) int {
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
@@ -0,0 +1,75 @@
+package shard
+
+import (
+	"fmt"
+	"testing"
+)
+
+func testPubkeys(n int) []string {
+	pubkeys := make([]string, n)
+	for i := range pubkeys {
+		pubkeys[i] = fmt.Sprintf("0x%096x", i)
+	}
+	return pubkeys
+}
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		pubkey string
+		count  int
+		want   int
+	}{
+		{name: "single shard", pubkey: "0xaa", count: 1, want: 0},
+		{name: "no shards", pubkey: "0xaa", count: 0, want: 0},
+		{name: "case of the pubkey", pubkey: "0xAA", count: 7, want: Of("0xaa", 7)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Of(tt.pubkey, tt.count); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOfStable checks the jump hash property: growing from n to n+1 shards only moves pubkeys to the new shard
+func TestOfStable(t *testing.T) {
+	pubkeys := testPubkeys(10000)
+	for _, count := range []int{1, 2, 3, 4, 5, 8, 10, 16} {
+		t.Run(fmt.Sprintf("%d to %d", count, count+1), func(t *testing.T) {
+			moved := 0
+			for _, pubkey := range pubkeys {
+				before, after := Of(pubkey, count), Of(pubkey, count+1)
+				if after < 0 || after > count {
+					t.Fatalf("%s got shard %d of %d", pubkey, after, count+1)
+				}
+				if before != after {
+					if after != count {
+						t.Fatalf("%s moved from shard %d to %d rather than the new shard %d", pubkey, before, after, count)
+					}
+					moved++
+				}
+			}
+			// about 1/(n+1) of the pubkeys move, allow for the spread of 10000 keys
+			want := len(pubkeys) / (count + 1)
+			if moved < want*8/10 || moved > want*12/10 {
+				t.Errorf("moved %d pubkeys, want about %d", moved, want)
+			}
+		})
+	}
+}
+
+func TestOfBalanced(t *testing.T) {
+	pubkeys := testPubkeys(10000)
+	const count = 4
+	sizes := make([]int, count)
+	for _, pubkey := range pubkeys {
+		sizes[Of(pubkey, count)]++
+	}
+	for shard, size := range sizes {
+		if want := len(pubkeys) / count; size < want*8/10 || size > want*12/10 {
+			t.Errorf("shard %d has %d pubkeys, want about %d", shard, size, want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package snapshot
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/0xste/validator-stats/internal/validator"
+)
+
+type ChangeKind string
+
+const (
+	NewCondition      ChangeKind = "new_condition"
+	ResolvedCondition ChangeKind = "resolved_condition"
+	StatusChange      ChangeKind = "status"
+	NewlySlashed      ChangeKind = "slashed"
+	CredentialsChange ChangeKind = "withdrawal_credentials"
+	NewValidator      ChangeKind = "new_validator"
+	RemovedValidator  ChangeKind = "removed_validator"
+)
+
+const conditionDayLayout = "2006-01-02"
+
+// Change is a single difference for a validator between two snapshots
+type Change struct {
+	Pubkey string
+	Group  string
+	Kind   ChangeKind
+	From   string
+	To     string
+	Detail string
+}
+
+// Diff reports what changed between two snapshots, daily conditions that only fell out of the
+// time range of the newer snapshot are not reported as resolved. A new validator is reported without its conditions
+// and a validator that couldn't be checked in either snapshot isn't compared at all, its conditions are unknown
+func Diff(from, to *Snapshot) []Change {
+	before := byPubkey(from.Healths)
+	after := byPubkey(to.Healths)
+	cutoff := to.Taken.Add(-to.Lookback)
+
+	var changes []Change
+	for _, pubkey := range pubkeys(before, after) {
+		old, current := before[pubkey], after[pubkey]
+		if failed(old) || failed(current) {
+			continue
+		}
+		group := groupOf(old, current)
+		switch {
+		case old == nil:
+			changes = append(changes, Change{Pubkey: pubkey, Group: group, Kind: NewValidator, To: current.Info.Data.Status})
+			continue
+		case current == nil:
+			changes = append(changes, Change{Pubkey: pubkey, Group: group, Kind: RemovedValidator, From: old.Info.Data.Status})
+			continue
+		default:
+			if old.Info.Data.Status != current.Info.Data.Status {
+				changes = append(changes, Change{Pubkey: pubkey, Group: group, Kind: StatusChange, From: old.Info.Data.Status, To: current.Info.Data.Status})
+			}
+			if !old.Info.Data.Slashed && current.Info.Data.Slashed {
+				changes = append(changes, Change{Pubkey: pubkey, Group: group, Kind: NewlySlashed, From: "false", To: "true"})
+			}
+			if old.Info.Data.Withdrawalcredentials != current.Info.Data.Withdrawalcredentials {
+				changes = append(changes, Change{Pubkey: pubkey, Group: group, Kind: CredentialsChange, From: old.Info.Data.Withdrawalcredentials, To: current.Info.Data.Withdrawalcredentials})
+			}
+		}
+
+		oldConditions, conditions := conditionsOf(old), conditionsOf(current)
+		for _, key := range sortedKeys(conditions) {
+			if _, ok := oldConditions[key]; !ok {
+				condition := conditions[key]
+				changes = append(changes, Change{Pubkey: pubkey, Group: group, Kind: NewCondition, To: string(condition.IssueType), Detail: describe(condition)})
+			}
+		}
+		for _, key := range sortedKeys(oldConditions) {
+			condition := oldConditions[key]
+			if _, ok := conditions[key]; ok || (condition.IssueType.Daily() && condition.Day.Before(cutoff)) {
+				continue
+			}
+			changes = append(changes, Change{Pubkey: pubkey, Group: group, Kind: ResolvedCondition, From: string(condition.IssueType), Detail: describe(condition)})
+		}
+	}
+	return changes
+}
+
+func failed(health *validator.Health) bool {
+	return health != nil && health.Error != ""
+}
+
+// groupOf prefers the group of the newer snapshot in case a validator moved between groups
+func groupOf(old, current *validator.Health) string {
+	if current != nil {
+		return current.Group
+	}
+	return old.Group
+}
+
+func byPubkey(healths []*validator.Health) map[string]*validator.Health {
+	m := make(map[string]*validator.Health, len(healths))
+	for _, health := range healths {
+		m[health.Info.Data.Pubkey] = health
+	}
+	return m
+}
+
+func pubkeys(maps ...map[string]*validator.Health) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for pubkey := range m {
+			if !seen[pubkey] {
+				seen[pubkey] = true
+				keys = append(keys, pubkey)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// conditionsOf keys daily conditions on their issue type and day, the others only on their issue type
+// as they are raised at the time of each run
+func conditionsOf(health *validator.Health) map[string]validator.Condition {
+	conditions := make(map[string]validator.Condition)
+	if health == nil {
+		return conditions
+	}
+	for _, condition := range health.Conditions[health.Info.Data.Pubkey] {
+		key := string(condition.IssueType)
+		if condition.IssueType.Daily() {
+			key += "|" + condition.Day.UTC().Format(conditionDayLayout)
+		}
+		conditions[key] = condition
+	}
+	return conditions
+}
+
+func sortedKeys(m map[string]validator.Condition) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func describe(condition validator.Condition) string {
+	if condition.IssueType.Daily() {
+		return fmt.Sprintf("%d on %s", condition.Count, condition.Day.UTC().Format(conditionDayLayout))
+	}
+	return fmt.Sprintf("%d", condition.Count)
+}
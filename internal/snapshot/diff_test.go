@@ -0,0 +1,92 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+func TestDiff(t *testing.T) {
+	taken := time.Date(2026, 10, 18, 6, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time {
+		return time.Date(2026, 10, n, 12, 0, 23, 0, time.UTC)
+	}
+	health := func(pubkey, status string, conditions ...validator.Condition) *validator.Health {
+		return &validator.Health{
+			Info:       beacon.Validator{Data: beacon.ValidatorData{Pubkey: pubkey, Status: status}},
+			Conditions: map[string][]validator.Condition{pubkey: conditions},
+			Group:      "client-a",
+		}
+	}
+	failed := func(pubkey string) *validator.Health {
+		h := health(pubkey, "")
+		h.Error = "429 too many requests"
+		return h
+	}
+	missed := validator.Condition{Day: day(17), Count: 3, IssueType: "missed_attestation"}
+	exit := validator.Condition{Day: day(17), Count: 180000, IssueType: "exit_epoch"}
+
+	tests := []struct {
+		name     string
+		from, to []*validator.Health
+		want     []Change
+	}{
+		{
+			name: "unchanged",
+			from: []*validator.Health{health("0xaa", "active_online", missed)},
+			to:   []*validator.Health{health("0xaa", "active_online", missed)},
+		},
+		{
+			name: "new validator without its conditions",
+			to:   []*validator.Health{health("0xaa", "active_online", missed, exit)},
+			want: []Change{{Pubkey: "0xaa", Group: "client-a", Kind: NewValidator, To: "active_online"}},
+		},
+		{
+			name: "removed validator",
+			from: []*validator.Health{health("0xaa", "active_online", missed)},
+			want: []Change{{Pubkey: "0xaa", Group: "client-a", Kind: RemovedValidator, From: "active_online"}},
+		},
+		{
+			name: "failed validator isn't removed or resolved",
+			from: []*validator.Health{health("0xaa", "active_online", missed)},
+			to:   []*validator.Health{failed("0xaa")},
+		},
+		{
+			name: "failed validator isn't new",
+			from: []*validator.Health{failed("0xaa")},
+			to:   []*validator.Health{health("0xaa", "active_online", missed)},
+		},
+		{
+			name: "status and conditions",
+			from: []*validator.Health{health("0xaa", "active_online", missed)},
+			to:   []*validator.Health{health("0xaa", "active_exiting", exit)},
+			want: []Change{
+				{Pubkey: "0xaa", Group: "client-a", Kind: StatusChange, From: "active_online", To: "active_exiting"},
+				{Pubkey: "0xaa", Group: "client-a", Kind: NewCondition, To: "exit_epoch", Detail: "180000"},
+				{Pubkey: "0xaa", Group: "client-a", Kind: ResolvedCondition, From: "missed_attestation", Detail: "3 on 2026-10-17"},
+			},
+		},
+		{
+			name: "daily condition outside the time range isn't resolved",
+			from: []*validator.Health{health("0xaa", "active_online", validator.Condition{Day: day(1), Count: 1, IssueType: "missed_attestation"})},
+			to:   []*validator.Health{health("0xaa", "active_online")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := &Snapshot{Taken: taken.Add(-24 * time.Hour), Lookback: 7 * 24 * time.Hour, Healths: tt.from}
+			to := &Snapshot{Taken: taken, Lookback: 7 * 24 * time.Hour, Healths: tt.to}
+			got := Diff(from, to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d changes %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got[i] != want {
+					t.Errorf("change %d\n got %+v\nwant %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package snapshot
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/pkg/errors"
+)
+
+const (
+	extension  = ".json.gz"
+	fileLayout = "20060102T150405Z"
+	merged     = "merged"
+)
+
+// Snapshot is the full result of a run
+type Snapshot struct {
+	Taken    time.Time
+	Lookback time.Duration
+	Healths  []*validator.Health
+	// Merged snapshots combine the snapshots of sharded scans
+	Merged bool `json:",omitempty"`
+	// Shard is the index of the scan when it was one of Shards, Shards is 0 for a scan that wasn't sharded
+	Shard  int `json:",omitempty"`
+	Shards int `json:",omitempty"`
+}
+
+// Kind is merged for merged snapshots, shard-N for the snapshots of sharded scans and empty otherwise
+func (s *Snapshot) Kind() string {
+	switch {
+	case s.Merged:
+		return merged
+	case s.Shards > 1:
+		return fmt.Sprintf("shard-%d", s.Shard)
+	}
+	return ""
+}
+
+// KindOf is the Kind of the snapshot saved at path
+func KindOf(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), extension)
+	_, kind, _ := strings.Cut(name, ".")
+	return kind
+}
+
+// OfKind returns the paths of the snapshots of kind, keeping their order
+func OfKind(paths []string, kind string) []string {
+	var matching []string
+	for _, path := range paths {
+		if KindOf(path) == kind {
+			matching = append(matching, path)
+		}
+	}
+	return matching
+}
+
+// Save writes the snapshot to dir named by the time it was taken and its kind, returning the path. Merged and shard
+// snapshots have their kind in the name, e.g. 20261018T060000Z.shard-1.json.gz, so they can't overwrite each other
+func Save(dir string, snapshot *Snapshot) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", errors.Wrap(err, "failed to create snapshot dir")
+	}
+	name := snapshot.Taken.UTC().Format(fileLayout)
+	if kind := snapshot.Kind(); kind != "" {
+		name += "." + kind
+	}
+	name += extension
+	path := filepath.Join(dir, name)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create snapshot file")
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	if err := json.NewEncoder(gz).Encode(snapshot); err != nil {
+		return "", errors.Wrap(err, "failed to encode snapshot")
+	}
+	if err := gz.Close(); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Load reads a snapshot written by Save
+func Load(path string) (*Snapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read snapshot %s", path)
+	}
+	defer gz.Close()
+	var snapshot Snapshot
+	if err := json.NewDecoder(gz).Decode(&snapshot); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode snapshot %s", path)
+	}
+	return &snapshot, nil
+}
+
+// List returns the snapshots in dir, oldest first
+func List(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+extension))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/0xste/validator-stats/internal/validator"
+	"github.com/0xste/validator-stats/pkg/beacon"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// validators holds a nested bucket per pubkey, keyed on the time of each run so records are kept in order
+var validatorsBucket = []byte("validators")
+
+// Store is the embedded history of every run
+type Store struct {
+	db *bolt.DB
+}
+
+// Record is what was known about a validator at the time of a run
+type Record struct {
+	Taken      time.Time
+	Data       beacon.ValidatorData
+	Conditions []validator.Condition
+	Labels     map[string]string
+	Group      string
+}
+
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open store %s", path)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(validatorsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record adds the results of a run taken at taken
+func (s *Store) Record(taken time.Time, healths []*validator.Health) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		validators := tx.Bucket(validatorsBucket)
+		for _, health := range healths {
+			pubkey := health.Info.Data.Pubkey
+			bucket, err := validators.CreateBucketIfNotExists([]byte(pubkey))
+			if err != nil {
+				return err
+			}
+			value, err := json.Marshal(Record{
+				Taken:      taken,
+				Data:       health.Info.Data,
+				Conditions: health.Conditions[pubkey],
+				Labels:     health.Labels,
+				Group:      health.Group,
+			})
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(timeKey(taken), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// History returns every record of pubkey, oldest first
+func (s *Store) History(pubkey string) ([]Record, error) {
+	var records []Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(validatorsBucket).Bucket([]byte(pubkey))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, value []byte) error {
+			var record Record
+			if err := json.Unmarshal(value, &record); err != nil {
+				return err
+			}
+			records = append(records, record)
+			return nil
+		})
+	})
+	return records, err
+}
+
+// Slashed returns the first record in [since, until) of each validator that was slashed
+func (s *Store) Slashed(since, until time.Time) ([]Record, error) {
+	var records []Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(validatorsBucket).ForEach(func(pubkey, _ []byte) error {
+			cursor := tx.Bucket(validatorsBucket).Bucket(pubkey).Cursor()
+			end := timeKey(until)
+			for key, value := cursor.Seek(timeKey(since)); key != nil && bytes.Compare(key, end) < 0; key, value = cursor.Next() {
+				var record Record
+				if err := json.Unmarshal(value, &record); err != nil {
+					return err
+				}
+				if record.Data.Slashed {
+					records = append(records, record)
+					return nil
+				}
+			}
+			return nil
+		})
+	})
+	return records, err
+}
+
+// timeKey sorts lexically in time order
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"context"
+
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+// AttestationPerformance holds the beaconcha.in attestation scores of a validator, nil fields were not returned
+type AttestationPerformance struct {
+	Effectiveness *float64
+	Efficiency    *float64
+}
+
+// PrefetchAttestationPerformance loads the attestation scores for pubkeys in batches so that
+// GetValidatorHealth doesn't need extra requests per validator
+func (c *Client) PrefetchAttestationPerformance(pubkeys []string) error {
+	for start := 0; start < len(pubkeys); start += beacon.MaxValidatorsPerRequest {
+		end := start + beacon.MaxValidatorsPerRequest
+		if end > len(pubkeys) {
+			end = len(pubkeys)
+		}
+		if err := c.fetchAttestationPerformance(context.Background(), pubkeys[start:end]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getAttestationPerformance looks up the scores for a validator index, fetching them when they weren't prefetched
+func (c *Client) getAttestationPerformance(pubkey string, index int) (*AttestationPerformance, error) {
+	c.attestationMu.Lock()
+	performance, ok := c.attestation[index]
+	c.attestationMu.Unlock()
+	if ok {
+		return performance, nil
+	}
+
+	if err := c.fetchAttestationPerformance(context.Background(), pubkey); err != nil {
+		return nil, err
+	}
+
+	c.attestationMu.Lock()
+	defer c.attestationMu.Unlock()
+	return c.performance(index), nil
+}
+
+func (c *Client) fetchAttestationPerformance(ctx context.Context, pubkeys ...string) error {
+	effectiveness, err := c.beaconClient.GetAttestationEffectiveness(ctx, pubkeys...)
+	c.track()
+	if err != nil {
+		return err
+	}
+	efficiency, err := c.beaconClient.GetAttestationEfficiency(ctx, pubkeys...)
+	c.track()
+	if err != nil {
+		return err
+	}
+
+	c.attestationMu.Lock()
+	defer c.attestationMu.Unlock()
+	for _, data := range effectiveness.Data {
+		value := data.AttestationEffectiveness
+		c.performance(data.Validatorindex).Effectiveness = &value
+	}
+	for _, data := range efficiency.Data {
+		value := data.AttestationEfficiency
+		c.performance(data.Validatorindex).Efficiency = &value
+	}
+	return nil
+}
+
+// performance returns the cached entry for index, the caller must hold attestationMu
+func (c *Client) performance(index int) *AttestationPerformance {
+	if _, ok := c.attestation[index]; !ok {
+		c.attestation[index] = &AttestationPerformance{}
+	}
+	return c.attestation[index]
+}
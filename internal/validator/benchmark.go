@@ -0,0 +1,120 @@
+package validator
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+const daysPerYear = 365
+
+// Benchmark compares the consensus layer APR of a validator with the ETH.STORE reference rate over the same days
+type Benchmark struct {
+	Pubkey          string
+	Days            int
+	Apr             float64
+	EthStoreApr     float64
+	GapPct          float64
+	Underperforming bool
+}
+
+// GetBenchmark derives the validator APR from the daily balances in health.Stats and compares it with ETH.STORE,
+// the validator is flagged as underperforming when it earns more than threshold percent below the reference rate
+func (c *Client) GetBenchmark(health *Health, lookback time.Duration, threshold float64) (*Benchmark, error) {
+	benchmark := &Benchmark{Pubkey: health.Info.Data.Pubkey}
+	if health.Stats == nil {
+		return benchmark, nil
+	}
+
+	timeThreshold := time.Now().Add(-lookback)
+
+	var income, effectiveBalance int
+	days := make(map[int]bool)
+	for _, stat := range health.Stats.Data {
+		if !stat.DayEnd.After(timeThreshold) || stat.StartEffectiveBalance == 0 {
+			continue
+		}
+		income += dailyIncome(stat)
+		effectiveBalance += stat.StartEffectiveBalance
+		days[stat.Day] = true
+	}
+	benchmark.Days = len(days)
+	if benchmark.Days == 0 {
+		return benchmark, nil
+	}
+
+	storeApr, err := c.ethStoreAprSum(context.Background(), days)
+	if err != nil {
+		return nil, err
+	}
+	benchmark.Apr = float64(income) / float64(effectiveBalance) * daysPerYear
+	benchmark.EthStoreApr = storeApr / float64(benchmark.Days)
+	if benchmark.EthStoreApr != 0 {
+		benchmark.GapPct = (benchmark.Apr - benchmark.EthStoreApr) / benchmark.EthStoreApr * 100
+	}
+	benchmark.Underperforming = benchmark.GapPct < -threshold
+	return benchmark, nil
+}
+
+// ethStoreAprSum adds up the reference rate of the days the stats cover, a run of 31 or 7 consecutive days is
+// read from the average of its last day so a 90 day range takes a handful of requests rather than one per day
+func (c *Client) ethStoreAprSum(ctx context.Context, days map[int]bool) (float64, error) {
+	sorted := make([]int, 0, len(days))
+	for day := range days {
+		sorted = append(sorted, day)
+	}
+	sort.Ints(sorted)
+
+	var sum float64
+	for i := len(sorted) - 1; i >= 0; {
+		day := sorted[i]
+		ethStore, err := c.getEthStore(ctx, day)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case i >= 30 && sorted[i-30] == day-30 && ethStore.Data.AvgClApr31d != 0:
+			sum += ethStore.Data.AvgClApr31d * 31
+			i -= 31
+		case i >= 6 && sorted[i-6] == day-6 && ethStore.Data.AvgClApr7d != 0:
+			sum += ethStore.Data.AvgClApr7d * 7
+			i -= 7
+		default:
+			sum += ethStoreApr(ethStore)
+			i--
+		}
+	}
+	return sum, nil
+}
+
+// getEthStore caches the reference rate per beaconchain-day, every validator is compared against the same days
+func (c *Client) getEthStore(ctx context.Context, day int) (*beacon.EthStore, error) {
+	c.ethStoreMu.Lock()
+	defer c.ethStoreMu.Unlock()
+	if ethStore, ok := c.ethStore[day]; ok {
+		return ethStore, nil
+	}
+	ethStore, err := c.beaconClient.GetEthStore(ctx, strconv.Itoa(day))
+	c.track()
+	if err != nil {
+		return nil, err
+	}
+	c.ethStore[day] = ethStore
+	return ethStore, nil
+}
+
+// dailyIncome is the balance change for the day excluding deposits and withdrawals, in gwei
+func dailyIncome(stat beacon.Stat) int {
+	return stat.EndBalance - stat.StartBalance + stat.WithdrawalsAmount - stat.DepositsAmount
+}
+
+// ethStoreApr prefers the consensus layer rate as balances don't include execution layer rewards
+func ethStoreApr(ethStore *beacon.EthStore) float64 {
+	if ethStore.Data.ClApr != 0 {
+		return ethStore.Data.ClApr
+	}
+	return ethStore.Data.Apr
+}
@@ -0,0 +1,106 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+// testEthStore serves a cl_apr of 1 for every day, with 7 and 31 day averages of 2 and 3 unless noAverages
+func testEthStore(t *testing.T, noAverages bool) (*Client, *atomic.Int64) {
+	t.Helper()
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		avg7d, avg31d := 2.0, 3.0
+		if noAverages {
+			avg7d, avg31d = 0, 0
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"OK","data":{"day":%s,"apr":5,"cl_apr":1,"avgclapr7d":%v,"avgclapr31d":%v}}`, path.Base(r.URL.Path), avg7d, avg31d)
+	}))
+	t.Cleanup(server.Close)
+	beaconClient := beacon.NewClient(server.Client(), server.URL, 1000, time.Second)
+	return NewClient(beaconClient, nil), &requests
+}
+
+func days(from, to int) map[int]bool {
+	d := make(map[int]bool)
+	for day := from; day <= to; day++ {
+		d[day] = true
+	}
+	return d
+}
+
+func TestEthStoreAprSum(t *testing.T) {
+	merge := func(maps ...map[int]bool) map[int]bool {
+		merged := make(map[int]bool)
+		for _, m := range maps {
+			for day := range m {
+				merged[day] = true
+			}
+		}
+		return merged
+	}
+	tests := []struct {
+		name         string
+		days         map[int]bool
+		noAverages   bool
+		want         float64
+		wantRequests int64
+	}{
+		{name: "a few days", days: days(100, 102), want: 3, wantRequests: 3},
+		{name: "a week from its last day", days: days(100, 106), want: 14, wantRequests: 1},
+		{name: "a month from its last day", days: days(100, 130), want: 93, wantRequests: 1},
+		{name: "a month, a week and the rest", days: days(100, 139), want: 93 + 14 + 2, wantRequests: 4},
+		{name: "a gap breaks the run", days: merge(days(100, 106), days(110, 110)), want: 14 + 1, wantRequests: 2},
+		{name: "without averages every day is read", days: days(100, 106), noAverages: true, want: 7, wantRequests: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := testEthStore(t, tt.noAverages)
+			got, err := client.ethStoreAprSum(context.Background(), tt.days)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if requests.Load() != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", requests.Load(), tt.wantRequests)
+			}
+
+			// every validator is compared over the same days, the second one is served from the cache
+			if _, err := client.ethStoreAprSum(context.Background(), tt.days); err != nil {
+				t.Fatal(err)
+			}
+			if requests.Load() != tt.wantRequests {
+				t.Errorf("made %d requests for the same days again, want none", requests.Load()-tt.wantRequests)
+			}
+		})
+	}
+}
+
+func BenchmarkEthStoreAprSum(b *testing.B) {
+	client := NewClient(beacon.NewClient(http.DefaultClient, "", 0, 0), nil)
+	// a 90 day range already in the cache, only the run detection is measured
+	for day := 100; day < 190; day++ {
+		ethStore := &beacon.EthStore{}
+		ethStore.Data.ClApr, ethStore.Data.AvgClApr7d, ethStore.Data.AvgClApr31d = 1, 2, 3
+		client.ethStore[day] = ethStore
+	}
+	lookback := days(100, 189)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.ethStoreAprSum(context.Background(), lookback); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,7 +2,8 @@ package validator
 
 import (
 	"context"
-	"fmt"
+	"log"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -12,66 +13,119 @@ import (
 
 type Client struct {
 	requests     atomic.Int64
+	failures     atomic.Int64
+	checked      atomic.Int64
 	promClient   *prom.Client
 	beaconClient *beacon.Client
+	rules        Rules
+
+	ethStoreMu sync.Mutex
+	ethStore   map[int]*beacon.EthStore
+
+	attestationMu sync.Mutex
+	attestation   map[int]*AttestationPerformance
 }
 
-func NewClient(beaconClient *beacon.Client, promClient *prom.Client) *Client {
-	if promClient != nil {
-		return &Client{
-			promClient:   promClient,
-			beaconClient: beaconClient,
-		}
-	}
-	return &Client{
+// Rules are the thresholds beyond which a validator is reported with a condition
+type Rules struct {
+	// MinAttestationEffectiveness is the lowest acceptable effectiveness percentage
+	MinAttestationEffectiveness float64
+	// MaxAttestationEfficiency is the highest acceptable efficiency, 1 is optimal and late inclusion increases it
+	MaxAttestationEfficiency float64
+}
+
+var DefaultRules = Rules{
+	MinAttestationEffectiveness: 80,
+	MaxAttestationEfficiency:    1.2,
+}
+
+func NewClient(beaconClient *beacon.Client, promClient *prom.Client, options ...func(c *Client)) *Client {
+	client := &Client{
 		beaconClient: beaconClient,
+		promClient:   promClient,
+		rules:        DefaultRules,
+		ethStore:     make(map[int]*beacon.EthStore),
+		attestation:  make(map[int]*AttestationPerformance),
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+func WithRules(rules Rules) func(reconfigure *Client) {
+	return func(c *Client) {
+		c.rules = rules
 	}
 }
 
+// Target is a validator to check along with the labels describing where it runs
+type Target struct {
+	Pubkey string
+	Labels map[string]string
+	// Group is the name of the validator group the target was configured in, empty without groups
+	Group string
+	// Policy overrides the client rules and adds the group expectations, nil uses the client rules
+	Policy *Policy
+}
+
 type Health struct {
-	Info       beacon.Validator
-	Conditions map[string][]Condition
+	Info        beacon.Validator
+	Conditions  map[string][]Condition
+	Stats       *beacon.Stats
+	Attestation *AttestationPerformance
+	// Labels describe where the validator runs e.g. the prometheus instance and job
+	Labels map[string]string
+	Group  string
+	// Error is why the validator couldn't be checked, the conditions are empty rather than describing the failure
+	Error string `json:",omitempty"`
 }
 
 func (c *Client) GetEstimatedDuration(items int) time.Duration {
 	return c.beaconClient.GetEstimatedDuration(items)
 }
 
-func (c *Client) logStatus() {
-	time.Sleep(time.Second * 30)
-}
-func (c *Client) GetValidatorHealth(pubkey string, lookback time.Duration) (*Health, error) {
+func (c *Client) GetValidatorHealth(target Target, lookback time.Duration) (_ *Health, err error) {
+	defer func() {
+		c.checked.Add(1)
+		if err != nil {
+			c.failures.Add(1)
+		}
+	}()
+	pubkey := target.Pubkey
 	validator, err := c.beaconClient.GetValidator(context.Background(), pubkey)
+	c.track()
 	if err != nil {
 		return &Health{
-			Info: beacon.Validator{ // make a mock health object
+			Info: beacon.Validator{
 				Status: "UNKNOWN",
-				Data:   beacon.ValidatorData{},
-			},
-			Conditions: map[string][]Condition{
-				pubkey: {{
-					Day:       time.Now(),
-					Count:     1,
-					IssueType: "NOT_EXISTS",
-				}},
+				Data:   beacon.ValidatorData{Pubkey: pubkey},
 			},
+			Conditions: map[string][]Condition{},
+			Labels:     target.Labels,
+			Group:      target.Group,
+			Error:      err.Error(),
 		}, err
 	}
 
 	stats, err := c.beaconClient.GetValidatorStats(context.Background(), 90, validator.Data.Validatorindex)
+	c.track()
 	if err != nil {
 		return &Health{
-			Info: *validator,
-			Conditions: map[string][]Condition{
-				pubkey: {Condition{
-					Day:       time.Now(),
-					Count:     1,
-					IssueType: IssueType(err.Error()),
-				}},
-			},
+			Info:       *validator,
+			Conditions: map[string][]Condition{},
+			Labels:     target.Labels,
+			Group:      target.Group,
+			Error:      err.Error(),
 		}, err
 	}
 
+	attestation, err := c.getAttestationPerformance(pubkey, validator.Data.Validatorindex)
+	if err != nil {
+		log.Printf("no attestation performance for %s: %s\n", pubkey, err)
+		attestation = &AttestationPerformance{}
+	}
+
 	pkErrors := make(map[string][]Condition)
 
 	timeThreshold := time.Now().Add(-lookback)
@@ -112,48 +166,66 @@ func (c *Client) GetValidatorHealth(pubkey string, lookback time.Duration) (*Hea
 				IssueType: slashingAttester,
 			})
 		}
+	}
 
-		// real-time stats
-		if validator.Data.Status != "active_online" {
-			pkErrors[validator.Data.Pubkey] = append(pkErrors[validator.Data.Pubkey], Condition{
-				Day:       time.Now(),
-				Count:     1,
-				IssueType: IssueType(fmt.Sprintf("status_%s", validator.Data.Status)),
-			})
-		}
-		if validator.Data.Slashed {
-			pkErrors[validator.Data.Pubkey] = append(pkErrors[validator.Data.Pubkey], Condition{
-				Day:       time.Now(),
-				Count:     1,
-				IssueType: "slashed",
-			})
-		}
-		if validator.Data.Exitepoch < 180600 {
-			pkErrors[validator.Data.Pubkey] = append(pkErrors[validator.Data.Pubkey], Condition{
-				Day:       time.Now(),
-				Count:     int(validator.Data.Exitepoch),
-				IssueType: "exit_epoch",
-			})
-		}
+	// real-time stats, once per validator rather than per day
+	if validator.Data.Status != "active_online" {
+		pkErrors[validator.Data.Pubkey] = append(pkErrors[validator.Data.Pubkey], Condition{
+			Day:       time.Now(),
+			Count:     1,
+			IssueType: statusIssue(validator.Data.Status),
+		})
+	}
+	if validator.Data.Slashed {
+		pkErrors[validator.Data.Pubkey] = append(pkErrors[validator.Data.Pubkey], Condition{
+			Day:       time.Now(),
+			Count:     1,
+			IssueType: slashedValidator,
+		})
+	}
+	if validator.Data.Exitepoch < 180600 {
+		pkErrors[validator.Data.Pubkey] = append(pkErrors[validator.Data.Pubkey], Condition{
+			Day:       time.Now(),
+			Count:     int(validator.Data.Exitepoch),
+			IssueType: exitEpoch,
+		})
+	}
+	rules := c.rules
+	if target.Policy != nil {
+		rules = target.Policy.Rules
+		pkErrors[validator.Data.Pubkey] = append(pkErrors[validator.Data.Pubkey], c.checkPolicy(*target.Policy, validator.Data, timeThreshold)...)
+	}
+	if attestation.Effectiveness != nil && *attestation.Effectiveness < rules.MinAttestationEffectiveness {
+		pkErrors[validator.Data.Pubkey] = append(pkErrors[validator.Data.Pubkey], Condition{
+			Day:       time.Now(),
+			Count:     1,
+			IssueType: lowAttestationEffectiveness,
+		})
 	}
+	if attestation.Efficiency != nil && *attestation.Efficiency > rules.MaxAttestationEfficiency {
+		pkErrors[validator.Data.Pubkey] = append(pkErrors[validator.Data.Pubkey], Condition{
+			Day:       time.Now(),
+			Count:     1,
+			IssueType: poorAttestationEfficiency,
+		})
+	}
+
 	return &Health{
-		Info:       *validator,
-		Conditions: pkErrors,
+		Info:        *validator,
+		Conditions:  pkErrors,
+		Stats:       stats,
+		Attestation: attestation,
+		Labels:      target.Labels,
+		Group:       target.Group,
 	}, nil
 }
 
-type IssueType string
-
-const (
-	missedBlock       IssueType = "missed_block"
-	missedAttestation IssueType = "missed_attestation"
-	missedSync        IssueType = "missed_sync"
-	slashingAttester  IssueType = "slashing_attester"
-	slashingProposer  IssueType = "slashing_propoer"
-)
-
 type Condition struct {
 	Day       time.Time
 	Count     int
 	IssueType IssueType
+	// Missed are the duties behind a missed_attestation, only set by DrillDownAttestations
+	Missed []Duty
+	// Evidence is what local telemetry saw, only set by CollectEvidence
+	Evidence []Evidence
 }
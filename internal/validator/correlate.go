@@ -0,0 +1,112 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const dayLayout = "2006-01-02"
+
+// Outage is a set of validators sharing the same labels that reported the same issue on the same day or epoch
+type Outage struct {
+	// Labels are the values of the correlation labels shared by the validators, empty when they have none
+	Labels    map[string]string
+	IssueType IssueType
+	Day       string
+	// Epoch is only set when the outage was correlated from drill-down duties
+	Epoch      *int
+	Validators []string
+	// Groups are the distinct groups of the validators
+	Groups []string
+	Count  int
+}
+
+// Cause describes the likely shared cause e.g. "instance=node-1: 140 validators missed_attestation on 2026-10-02"
+func (o Outage) Cause() string {
+	cluster := "all validators"
+	if len(o.Labels) > 0 {
+		var pairs []string
+		for name, value := range o.Labels {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", name, value))
+		}
+		sort.Strings(pairs)
+		cluster = strings.Join(pairs, ",")
+	}
+	when := o.Day
+	if o.Epoch != nil {
+		when = fmt.Sprintf("%s epoch %d", o.Day, *o.Epoch)
+	}
+	return fmt.Sprintf("%s: %d validators %s on %s", cluster, len(o.Validators), o.IssueType, when)
+}
+
+// Correlate groups the conditions of all healths by the values of labels, the issue type and the day, using the
+// missed epochs instead of the day when drill-down duties exist. Groups with fewer than minValidators are dropped
+func Correlate(healths []*Health, labels []string, minValidators int) []Outage {
+	outages := make(map[string]*Outage)
+	var keys []string
+	add := func(health *Health, issueType IssueType, day string, epoch *int, count int) {
+		cluster := make(map[string]string)
+		var values []string
+		for _, label := range labels {
+			if value, ok := health.Labels[label]; ok {
+				cluster[label] = value
+			}
+			values = append(values, health.Labels[label])
+		}
+		key := fmt.Sprintf("%s|%s|%s", strings.Join(values, ","), issueType, day)
+		if epoch != nil {
+			key = fmt.Sprintf("%s|%d", key, *epoch)
+		}
+		outage, ok := outages[key]
+		if !ok {
+			outage = &Outage{Labels: cluster, IssueType: issueType, Day: day, Epoch: epoch}
+			outages[key] = outage
+			keys = append(keys, key)
+		}
+		pubkey := health.Info.Data.Pubkey
+		if n := len(outage.Validators); n == 0 || outage.Validators[n-1] != pubkey {
+			outage.Validators = append(outage.Validators, pubkey)
+			if health.Group != "" && !contains(outage.Groups, health.Group) {
+				outage.Groups = append(outage.Groups, health.Group)
+			}
+		}
+		outage.Count += count
+	}
+
+	for _, health := range healths {
+		for _, conditions := range health.Conditions {
+			for _, condition := range conditions {
+				day := condition.Day.UTC().Format(dayLayout)
+				if len(condition.Missed) == 0 {
+					add(health, condition.IssueType, day, nil, condition.Occurrences())
+					continue
+				}
+				for _, duty := range condition.Missed {
+					epoch := duty.Epoch
+					add(health, condition.IssueType, day, &epoch, 1)
+				}
+			}
+		}
+	}
+
+	var correlated []Outage
+	for _, key := range keys {
+		if len(outages[key].Validators) >= minValidators {
+			correlated = append(correlated, *outages[key])
+		}
+	}
+	sort.SliceStable(correlated, func(i, j int) bool {
+		return len(correlated[i].Validators) > len(correlated[j].Validators)
+	})
+	return correlated
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
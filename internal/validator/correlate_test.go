@@ -0,0 +1,134 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+func TestCorrelate(t *testing.T) {
+	day := time.Date(2026, 10, 2, 12, 0, 23, 0, time.UTC)
+	health := func(pubkey, instance, group string, conditions ...Condition) *Health {
+		return &Health{
+			Info:       beacon.Validator{Data: beacon.ValidatorData{Pubkey: pubkey}},
+			Conditions: map[string][]Condition{pubkey: conditions},
+			Labels:     map[string]string{"instance": instance},
+			Group:      group,
+		}
+	}
+	missed := func(count int, epochs ...int) Condition {
+		condition := Condition{Day: day, Count: count, IssueType: missedAttestation}
+		for _, epoch := range epochs {
+			condition.Missed = append(condition.Missed, Duty{Epoch: epoch})
+		}
+		return condition
+	}
+	epoch := func(n int) *int { return &n }
+
+	tests := []struct {
+		name          string
+		healths       []*Health
+		labels        []string
+		minValidators int
+		want          []Outage
+	}{
+		{
+			name: "same instance and day",
+			healths: []*Health{
+				health("0xaa", "node-1", "client-a", missed(2)),
+				health("0xbb", "node-1", "client-b", missed(3)),
+				health("0xcc", "node-2", "client-a", missed(1)),
+			},
+			labels:        []string{"instance"},
+			minValidators: 2,
+			want: []Outage{
+				{Labels: map[string]string{"instance": "node-1"}, IssueType: missedAttestation, Day: "2026-10-02", Validators: []string{"0xaa", "0xbb"}, Groups: []string{"client-a", "client-b"}, Count: 5},
+			},
+		},
+		{
+			name: "without labels every validator is one cluster",
+			healths: []*Health{
+				health("0xaa", "node-1", "", missed(2)),
+				health("0xbb", "node-2", "", missed(3)),
+			},
+			minValidators: 2,
+			want: []Outage{
+				{Labels: map[string]string{}, IssueType: missedAttestation, Day: "2026-10-02", Validators: []string{"0xaa", "0xbb"}, Count: 5},
+			},
+		},
+		{
+			name: "drill-down duties correlate on the epoch",
+			healths: []*Health{
+				health("0xaa", "node-1", "client-a", missed(2, 100, 101)),
+				health("0xbb", "node-1", "client-a", missed(1, 101)),
+			},
+			labels:        []string{"instance"},
+			minValidators: 2,
+			want: []Outage{
+				{Labels: map[string]string{"instance": "node-1"}, IssueType: missedAttestation, Day: "2026-10-02", Epoch: epoch(101), Validators: []string{"0xaa", "0xbb"}, Groups: []string{"client-a"}, Count: 2},
+			},
+		},
+		{
+			name: "conditions that aren't daily count once",
+			healths: []*Health{
+				health("0xaa", "node-1", "client-a", Condition{Day: day, Count: 180000, IssueType: exitEpoch}),
+				health("0xbb", "node-1", "client-a", Condition{Day: day, Count: 180000, IssueType: exitEpoch}),
+			},
+			labels:        []string{"instance"},
+			minValidators: 2,
+			want: []Outage{
+				{Labels: map[string]string{"instance": "node-1"}, IssueType: exitEpoch, Day: "2026-10-02", Validators: []string{"0xaa", "0xbb"}, Groups: []string{"client-a"}, Count: 2},
+			},
+		},
+		{
+			name: "below the minimum",
+			healths: []*Health{
+				health("0xaa", "node-1", "client-a", missed(2)),
+			},
+			labels:        []string{"instance"},
+			minValidators: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Correlate(tt.healths, tt.labels, tt.minValidators)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d outages %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !reflect.DeepEqual(got[i], want) {
+					t.Errorf("outage %d\n got %+v\nwant %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestOutageCause(t *testing.T) {
+	epoch := 101
+	tests := []struct {
+		name   string
+		outage Outage
+		want   string
+	}{
+		{
+			name:   "labels",
+			outage: Outage{Labels: map[string]string{"job": "prysm", "instance": "node-1"}, IssueType: missedAttestation, Day: "2026-10-02", Validators: []string{"0xaa", "0xbb"}},
+			want:   "instance=node-1,job=prysm: 2 validators missed_attestation on 2026-10-02",
+		},
+		{
+			name:   "no labels and an epoch",
+			outage: Outage{IssueType: missedAttestation, Day: "2026-10-02", Epoch: &epoch, Validators: []string{"0xaa"}},
+			want:   "all validators: 1 validators missed_attestation on 2026-10-02 epoch 101",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.outage.Cause(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
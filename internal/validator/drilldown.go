@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"context"
+
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+// Duty identifies a single attestation duty on chain
+type Duty struct {
+	Epoch int
+	Slot  int
+}
+
+// DrillDownAttestations attaches the missed epochs and slots to each missed_attestation condition of health,
+// only the most recent epochs are served by beaconcha.in so older days are left without duties
+func (c *Client) DrillDownAttestations(health *Health) error {
+	pubkey := health.Info.Data.Pubkey
+	if !hasIssue(health.Conditions[pubkey], missedAttestation) || health.Stats == nil {
+		return nil
+	}
+
+	// conditions are keyed on the end of the day, duties on the beaconchain-day
+	days := make(map[int64]int)
+	for _, stat := range health.Stats.Data {
+		days[stat.DayEnd.Unix()] = stat.Day
+	}
+
+	attestations, err := c.beaconClient.GetValidatorAttestations(context.Background(), pubkey)
+	c.track()
+	if err != nil {
+		return err
+	}
+	head := attestations.HeadEpoch()
+	missed := make(map[int][]Duty)
+	for _, attestation := range attestations.Data {
+		if !attestation.Missed(head) {
+			continue
+		}
+		day := attestation.Epoch / beacon.EpochsPerDay
+		missed[day] = append(missed[day], Duty{
+			Epoch: attestation.Epoch,
+			Slot:  attestation.AttesterSlot,
+		})
+	}
+
+	conditions := health.Conditions[pubkey]
+	for i, condition := range conditions {
+		if condition.IssueType != missedAttestation {
+			continue
+		}
+		if day, ok := days[condition.Day.Unix()]; ok {
+			conditions[i].Missed = missed[day]
+		}
+	}
+	return nil
+}
+
+func hasIssue(conditions []Condition, issueType IssueType) bool {
+	for _, condition := range conditions {
+		if condition.IssueType == issueType {
+			return true
+		}
+	}
+	return false
+}
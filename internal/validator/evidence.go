@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xste/validator-stats/pkg/prom"
+)
+
+// Evidence is what a local prometheus probe saw over the day of a condition
+type Evidence struct {
+	Probe string
+	prom.Observation
+}
+
+// Corroborated is true when local telemetry agrees with beaconcha.in that something went wrong,
+// nil when there is no local telemetry for the condition
+func (c Condition) Corroborated() *bool {
+	var seen, trouble bool
+	for _, evidence := range c.Evidence {
+		if evidence.Samples == 0 {
+			continue
+		}
+		seen = true
+		trouble = trouble || evidence.Trouble
+	}
+	if !seen {
+		return nil
+	}
+	return &trouble
+}
+
+// CollectEvidence runs probes against the local prometheus over the day before each condition of health
+func (c *Client) CollectEvidence(health *Health, probes []prom.Probe) error {
+	if c.promClient == nil {
+		return nil
+	}
+	pubkey := health.Info.Data.Pubkey
+	conditions := health.Conditions[pubkey]
+	for i, condition := range conditions {
+		for _, probe := range probes {
+			if !probeApplies(probe, condition.IssueType) {
+				continue
+			}
+			observation, err := c.promClient.Observe(context.Background(), probe, pubkey, health.Info.Data.Validatorindex, health.Labels, condition.Day.Add(-24*time.Hour), condition.Day)
+			if err != nil {
+				return err
+			}
+			conditions[i].Evidence = append(conditions[i].Evidence, Evidence{
+				Probe:       probe.Name,
+				Observation: *observation,
+			})
+		}
+	}
+	return nil
+}
+
+func probeApplies(probe prom.Probe, issueType IssueType) bool {
+	if len(probe.Issues) == 0 {
+		return true
+	}
+	for _, issue := range probe.Issues {
+		if IssueType(issue) == issueType {
+			return true
+		}
+	}
+	return false
+}
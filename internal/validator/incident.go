@@ -0,0 +1,89 @@
+package validator
+
+import (
+	"sort"
+	"time"
+)
+
+// Incident is a run of consecutive days on which a validator had the same daily issue
+type Incident struct {
+	Pubkey    string
+	Group     string
+	IssueType IssueType
+	// Start is the start of the first day and End the end of the last, days are beaconchain-days of 24h
+	Start time.Time
+	End   time.Time
+	Days  int
+	Count int
+	// PeakDay is the end of the day with the highest count, the earliest on a tie
+	PeakDay   time.Time
+	PeakCount int
+}
+
+func (i Incident) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// Incidents merges the daily conditions of the validator into incidents, issues describing the validator at the time
+// of the run such as its status are left out as they have no days to merge
+func (h *Health) Incidents() []Incident {
+	// conditions of the same issue on the same day are summed first, e.g. several fee recipient mismatches
+	perDay := make(map[IssueType][]Condition)
+	for _, condition := range h.Conditions[h.Info.Data.Pubkey] {
+		if !condition.IssueType.Daily() {
+			continue
+		}
+		days := perDay[condition.IssueType]
+		if i := dayIndex(days, condition.Day); i >= 0 {
+			days[i].Count += condition.Count
+			continue
+		}
+		perDay[condition.IssueType] = append(days, Condition{Day: condition.Day, Count: condition.Count, IssueType: condition.IssueType})
+	}
+
+	var incidents []Incident
+	for issueType, days := range perDay {
+		sort.Slice(days, func(i, j int) bool { return days[i].Day.Before(days[j].Day) })
+		var current *Incident
+		for _, day := range days {
+			if current == nil || !truncateDay(day.Day).Equal(truncateDay(current.End).AddDate(0, 0, 1)) {
+				if current != nil {
+					incidents = append(incidents, *current)
+				}
+				current = &Incident{
+					Pubkey:    h.Info.Data.Pubkey,
+					Group:     h.Group,
+					IssueType: issueType,
+					Start:     day.Day.Add(-24 * time.Hour),
+				}
+			}
+			current.End = day.Day
+			current.Days++
+			current.Count += day.Count
+			if day.Count > current.PeakCount {
+				current.PeakDay, current.PeakCount = day.Day, day.Count
+			}
+		}
+		incidents = append(incidents, *current)
+	}
+	sort.Slice(incidents, func(i, j int) bool {
+		if incidents[i].Start.Equal(incidents[j].Start) {
+			return incidents[i].IssueType < incidents[j].IssueType
+		}
+		return incidents[i].Start.Before(incidents[j].Start)
+	})
+	return incidents
+}
+
+func dayIndex(conditions []Condition, day time.Time) int {
+	for i, condition := range conditions {
+		if truncateDay(condition.Day).Equal(truncateDay(day)) {
+			return i
+		}
+	}
+	return -1
+}
+
+func truncateDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,139 @@
+package validator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+func TestIncidents(t *testing.T) {
+	// beaconchain-days end at 12:00:23 UTC on mainnet
+	day := func(n int) time.Time {
+		return time.Date(2026, 10, n, 12, 0, 23, 0, time.UTC)
+	}
+	condition := func(issueType IssueType, n, count int) Condition {
+		return Condition{Day: day(n), Count: count, IssueType: issueType}
+	}
+	tests := []struct {
+		name       string
+		conditions []Condition
+		want       []Incident
+	}{
+		{
+			name: "single day",
+			conditions: []Condition{
+				condition(missedAttestation, 3, 4),
+			},
+			want: []Incident{
+				{IssueType: missedAttestation, Start: day(2), End: day(3), Days: 1, Count: 4, PeakDay: day(3), PeakCount: 4},
+			},
+		},
+		{
+			name: "consecutive days merge",
+			conditions: []Condition{
+				condition(missedAttestation, 3, 4),
+				condition(missedAttestation, 1, 2),
+				condition(missedAttestation, 2, 9),
+			},
+			want: []Incident{
+				{IssueType: missedAttestation, Start: day(0), End: day(3), Days: 3, Count: 15, PeakDay: day(2), PeakCount: 9},
+			},
+		},
+		{
+			name: "a gap splits the run",
+			conditions: []Condition{
+				condition(missedAttestation, 1, 1),
+				condition(missedAttestation, 2, 1),
+				condition(missedAttestation, 4, 5),
+			},
+			want: []Incident{
+				{IssueType: missedAttestation, Start: day(0), End: day(2), Days: 2, Count: 2, PeakDay: day(1), PeakCount: 1},
+				{IssueType: missedAttestation, Start: day(3), End: day(4), Days: 1, Count: 5, PeakDay: day(4), PeakCount: 5},
+			},
+		},
+		{
+			name: "ties peak on the earliest day",
+			conditions: []Condition{
+				condition(missedSync, 6, 3),
+				condition(missedSync, 5, 3),
+				condition(missedSync, 7, 1),
+			},
+			want: []Incident{
+				{IssueType: missedSync, Start: day(4), End: day(7), Days: 3, Count: 7, PeakDay: day(5), PeakCount: 3},
+			},
+		},
+		{
+			name: "same day is summed",
+			conditions: []Condition{
+				condition(feeRecipientMismatch, 8, 1),
+				condition(feeRecipientMismatch, 8, 1),
+				condition(feeRecipientMismatch, 9, 1),
+			},
+			want: []Incident{
+				{IssueType: feeRecipientMismatch, Start: day(7), End: day(9), Days: 2, Count: 3, PeakDay: day(8), PeakCount: 2},
+			},
+		},
+		{
+			name: "issues are kept apart and sorted by start",
+			conditions: []Condition{
+				condition(missedSync, 2, 1),
+				condition(missedAttestation, 2, 1),
+				condition(missedBlock, 1, 1),
+			},
+			want: []Incident{
+				{IssueType: missedBlock, Start: day(0), End: day(1), Days: 1, Count: 1, PeakDay: day(1), PeakCount: 1},
+				{IssueType: missedAttestation, Start: day(1), End: day(2), Days: 1, Count: 1, PeakDay: day(2), PeakCount: 1},
+				{IssueType: missedSync, Start: day(1), End: day(2), Days: 1, Count: 1, PeakDay: day(2), PeakCount: 1},
+			},
+		},
+		{
+			name: "issues that aren't daily are left out",
+			conditions: []Condition{
+				{Day: day(3), Count: 180000, IssueType: exitEpoch},
+				{Day: day(3), Count: 1, IssueType: slashedValidator},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := &Health{
+				Info:       beacon.Validator{Data: beacon.ValidatorData{Pubkey: "0xaa"}},
+				Conditions: map[string][]Condition{"0xaa": tt.conditions},
+				Group:      "client-a",
+			}
+			got := health.Incidents()
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d incidents %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				want.Pubkey, want.Group = "0xaa", "client-a"
+				if got[i] != want {
+					t.Errorf("incident %d\n got %+v\nwant %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDayEnd(t *testing.T) {
+	genesis := time.Unix(1606824023, 0).UTC()
+	slotsPerDay := beacon.EpochsPerDay * slotsPerEpoch
+	tests := []struct {
+		name string
+		slot int
+		want time.Time
+	}{
+		{name: "first slot of a day", slot: 10 * slotsPerDay, want: genesis.Add(11 * 24 * time.Hour)},
+		{name: "last slot of a day", slot: 11*slotsPerDay - 1, want: genesis.Add(11 * 24 * time.Hour)},
+		{name: "next day", slot: 11 * slotsPerDay, want: genesis.Add(12 * 24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timestamp := genesis.Add(time.Duration(tt.slot) * secondsPerSlot)
+			if got := dayEnd(tt.slot, timestamp); !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package validator
+
+import "strings"
+
+type IssueType string
+
+const (
+	missedBlock       IssueType = "missed_block"
+	missedAttestation IssueType = "missed_attestation"
+	missedSync        IssueType = "missed_sync"
+	slashingAttester  IssueType = "slashing_attester"
+	slashingProposer  IssueType = "slashing_propoer"
+
+	lowAttestationEffectiveness IssueType = "low_attestation_effectiveness"
+	poorAttestationEfficiency   IssueType = "poor_attestation_efficiency"
+
+	slashedValidator IssueType = "slashed"
+	exitEpoch        IssueType = "exit_epoch"
+
+	withdrawalMismatch   IssueType = "withdrawal_address_mismatch"
+	feeRecipientMismatch IssueType = "fee_recipient_mismatch"
+
+	// statusPrefix is followed by the beaconcha.in status of a validator that isn't active_online
+	statusPrefix  = "status_"
+	statusUnknown = IssueType(statusPrefix + "unknown")
+)
+
+// Severity holds the condition counts at which an issue alerts, 0 disables that severity
+type Severity struct {
+	Warning  int
+	Critical int
+}
+
+// Issue describes an issue type of the catalogue
+type Issue struct {
+	Type        IssueType
+	Description string
+	// Daily issues come from the per day stats, the others describe the validator at the time of the run
+	Daily bool
+	// Status issues follow the beaconcha.in status and alert through the status metric rather than their own rule
+	Status bool
+	// Severity is the default alert threshold, compared with the sum of the counts over TIME_RANGE
+	Severity Severity
+}
+
+// Issues is the closed catalogue of issue types a condition can have
+var Issues = []Issue{
+	{Type: missedBlock, Daily: true, Severity: Severity{Warning: 1, Critical: 2},
+		Description: "block proposals missed on the day"},
+	{Type: missedAttestation, Daily: true, Severity: Severity{Warning: 10, Critical: 50},
+		Description: "attestations missed on the day"},
+	{Type: missedSync, Daily: true, Severity: Severity{Warning: 10, Critical: 50},
+		Description: "sync committee duties missed on the day"},
+	{Type: slashingAttester, Daily: true, Severity: Severity{Critical: 1},
+		Description: "attester slashings included on the day"},
+	{Type: slashingProposer, Daily: true, Severity: Severity{Critical: 1},
+		Description: "proposer slashings included on the day"},
+	{Type: lowAttestationEffectiveness, Severity: Severity{Warning: 1},
+		Description: "attestation effectiveness below the minimum of the rules"},
+	{Type: poorAttestationEfficiency, Severity: Severity{Warning: 1},
+		Description: "attestation efficiency above the maximum of the rules, attestations are included late"},
+	{Type: slashedValidator, Severity: Severity{Critical: 1},
+		Description: "the validator has been slashed"},
+	{Type: exitEpoch, Severity: Severity{Warning: 1},
+		Description: "the validator has an exit epoch, the count is the epoch"},
+	{Type: withdrawalMismatch, Severity: Severity{Critical: 1},
+		Description: "the withdrawal credentials don't match the withdrawal address of the group"},
+	{Type: feeRecipientMismatch, Daily: true, Severity: Severity{Critical: 1},
+		Description: "blocks proposed on the day paid a fee recipient other than the one of the group"},
+	{Type: statusPrefix + "deposited", Status: true, Description: "deposit seen, not yet eligible for activation"},
+	{Type: statusPrefix + "pending", Status: true, Description: "deposited, waiting in the activation queue"},
+	{Type: statusPrefix + "active_offline", Status: true, Description: "active but not attesting"},
+	{Type: statusPrefix + "exiting_online", Status: true, Description: "exit requested, still attesting"},
+	{Type: statusPrefix + "exiting_offline", Status: true, Description: "exit requested and not attesting"},
+	{Type: statusPrefix + "slashing_online", Status: true, Description: "being slashed, still attesting"},
+	{Type: statusPrefix + "slashing_offline", Status: true, Description: "being slashed and not attesting"},
+	{Type: statusPrefix + "exited", Status: true, Description: "exited, no longer has duties"},
+	{Type: statusPrefix + "slashed", Status: true, Description: "slashed and exited"},
+	{Type: statusUnknown, Status: true, Description: "a status missing from this catalogue, see the status column"},
+}
+
+// IssueTypes are the issues raised from stats and rules, status issues are left out as they alert on the status
+var IssueTypes = func() []IssueType {
+	var types []IssueType
+	for _, issue := range Issues {
+		if !issue.Status {
+			types = append(types, issue.Type)
+		}
+	}
+	return types
+}()
+
+// LookupIssue finds an issue type in the catalogue
+func LookupIssue(issueType IssueType) (Issue, bool) {
+	for _, issue := range Issues {
+		if issue.Type == issueType {
+			return issue, true
+		}
+	}
+	return Issue{}, false
+}
+
+func (i IssueType) Daily() bool {
+	issue, _ := LookupIssue(i)
+	return issue.Daily
+}
+
+// Occurrences is the count of a daily condition and 1 for the others, which describe the validator at the time
+// of the run and can carry another number as their count e.g. the epoch of exit_epoch
+func (c Condition) Occurrences() int {
+	if c.IssueType.Daily() {
+		return c.Count
+	}
+	return 1
+}
+
+// statusIssue is the issue of a validator that isn't active_online, statuses beaconcha.in adds later are status_unknown
+func statusIssue(status string) IssueType {
+	issueType := IssueType(statusPrefix + strings.ToLower(status))
+	if issue, ok := LookupIssue(issueType); ok && issue.Status {
+		return issueType
+	}
+	return statusUnknown
+}
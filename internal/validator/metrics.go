@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+// metric names exported for a run, prometheus alert rules and dashboards are built on these
+const (
+	MetricConditions            = "validator_health_conditions"
+	MetricStatus                = "validator_health_status"
+	MetricSlashed               = "validator_health_slashed"
+	MetricBalance               = "validator_health_balance_gwei"
+	MetricAttestationEffective  = "validator_health_attestation_effectiveness"
+	MetricAttestationEfficiency = "validator_health_attestation_efficiency"
+	MetricLastRun               = "validator_health_last_run_timestamp_seconds"
+)
+
+// Metrics turns the results of a run into gauges, labels are copied from each validator and
+// job and instance are renamed to exported_job and exported_instance so they don't clash with the target's labels.
+// A label that is invalid or clashes with the pubkey, status or issue_type labels is an error
+func Metrics(healths []*Health, labels []string) (prometheus.Gatherer, error) {
+	names := []string{"pubkey", "group"}
+	seen := map[string]bool{"pubkey": true, "group": true, "status": true, "issue_type": true}
+	for _, label := range labels {
+		name := metricLabel(label)
+		if seen[name] {
+			return nil, fmt.Errorf("label column %q clashes with the metric label %s", label, name)
+		}
+		if !model.LabelName(name).IsValid() || strings.HasPrefix(name, "__") {
+			return nil, fmt.Errorf("label column %q is not a valid metric label", label)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	conditions := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricConditions,
+		Help: "Sum of the condition counts within the time range by issue type.",
+	}, withLabel(names, "issue_type"))
+	status := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricStatus,
+		Help: "Set to 1 for the current beaconcha.in status of the validator.",
+	}, withLabel(names, "status"))
+	slashed := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricSlashed,
+		Help: "Set to 1 when the validator has been slashed.",
+	}, names)
+	balance := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricBalance,
+		Help: "Balance of the validator in gwei.",
+	}, names)
+	effectiveness := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricAttestationEffective,
+		Help: "Attestation effectiveness percentage reported by beaconcha.in.",
+	}, names)
+	efficiency := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricAttestationEfficiency,
+		Help: "Attestation efficiency reported by beaconcha.in, 1 is optimal.",
+	}, names)
+	lastRun := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: MetricLastRun,
+		Help: "Unix time the results were produced.",
+	})
+
+	registry := prometheus.NewRegistry()
+	for _, collector := range []prometheus.Collector{conditions, status, slashed, balance, effectiveness, efficiency, lastRun} {
+		if err := registry.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, health := range healths {
+		info := health.Info.Data
+		values := []string{info.Pubkey, health.Group}
+		for _, label := range labels {
+			values = append(values, health.Labels[label])
+		}
+
+		counts := make(map[IssueType]int)
+		for _, condition := range health.Conditions[info.Pubkey] {
+			counts[condition.IssueType] += condition.Occurrences()
+		}
+		for issueType, count := range counts {
+			conditions.WithLabelValues(withLabel(values, string(issueType))...).Set(float64(count))
+		}
+
+		status.WithLabelValues(withLabel(values, info.Status)...).Set(1)
+		if info.Slashed {
+			slashed.WithLabelValues(values...).Set(1)
+		} else {
+			slashed.WithLabelValues(values...).Set(0)
+		}
+		balance.WithLabelValues(values...).Set(float64(info.Balance))
+		if health.Attestation != nil && health.Attestation.Effectiveness != nil {
+			effectiveness.WithLabelValues(values...).Set(*health.Attestation.Effectiveness)
+		}
+		if health.Attestation != nil && health.Attestation.Efficiency != nil {
+			efficiency.WithLabelValues(values...).Set(*health.Attestation.Efficiency)
+		}
+	}
+	lastRun.Set(float64(time.Now().Unix()))
+	return registry, nil
+}
+
+func metricLabel(label string) string {
+	switch label {
+	case "job", "instance", "group":
+		return "exported_" + label
+	}
+	return strings.ReplaceAll(label, "-", "_")
+}
+
+// withLabel appends to a copy so label slices sharing a prefix don't overwrite each other
+func withLabel(labels []string, label string) []string {
+	return append(append(make([]string, 0, len(labels)+1), labels...), label)
+}
@@ -0,0 +1,45 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+func TestMetricsLabels(t *testing.T) {
+	healths := []*Health{{
+		Info:   beacon.Validator{Data: beacon.ValidatorData{Pubkey: "0xaa", Status: "active_online"}},
+		Labels: map[string]string{"instance": "node-1", "node-network": "mainnet"},
+	}}
+	tests := []struct {
+		name    string
+		labels  []string
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "renamed", labels: []string{"instance", "job", "group", "node-network"}},
+		{name: "pubkey", labels: []string{"pubkey"}, wantErr: true},
+		{name: "status", labels: []string{"status"}, wantErr: true},
+		{name: "issue type", labels: []string{"issue_type"}, wantErr: true},
+		{name: "duplicate after renaming", labels: []string{"node-network", "node_network"}, wantErr: true},
+		{name: "invalid", labels: []string{"node.network"}, wantErr: true},
+		{name: "reserved", labels: []string{"__name__"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics, err := Metrics(healths, tt.labels)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error for labels %v", tt.labels)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := metrics.Gather(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
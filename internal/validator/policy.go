@@ -0,0 +1,86 @@
+package validator
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+// Policy is what a group of validators is expected to look like, empty addresses are not checked
+type Policy struct {
+	Rules
+	// WithdrawalAddress is the execution address the 0x01 or 0x02 withdrawal credentials should point at
+	WithdrawalAddress string
+	// FeeRecipient is the execution address proposals should pay their fees to
+	FeeRecipient string
+}
+
+// checkPolicy raises a condition when the withdrawal credentials don't point at the expected address and one per day
+// with proposals paying another fee recipient, looking up proposals costs an extra request per validator
+func (c *Client) checkPolicy(policy Policy, data beacon.ValidatorData, since time.Time) []Condition {
+	var conditions []Condition
+	if policy.WithdrawalAddress != "" && !withdrawsTo(data.Withdrawalcredentials, policy.WithdrawalAddress) {
+		conditions = append(conditions, Condition{
+			Day:       time.Now(),
+			Count:     1,
+			IssueType: withdrawalMismatch,
+		})
+	}
+	if policy.FeeRecipient == "" {
+		return conditions
+	}
+
+	proposals, err := c.beaconClient.GetValidatorProposals(context.Background(), "", data.Pubkey)
+	c.track()
+	if err != nil {
+		log.Printf("no proposals to check the fee recipient of %s: %s\n", data.Pubkey, err)
+		return conditions
+	}
+	days := make(map[time.Time]int)
+	var order []time.Time
+	for _, proposal := range proposals.Data {
+		if proposal.ExecFeeRecipient == "" || strings.EqualFold(proposal.ExecFeeRecipient, policy.FeeRecipient) {
+			continue
+		}
+		proposed := time.Unix(int64(proposal.ExecTimestamp), 0).UTC()
+		if proposed.Before(since) {
+			continue
+		}
+		day := dayEnd(proposal.Slot, proposed)
+		if _, ok := days[day]; !ok {
+			order = append(order, day)
+		}
+		days[day]++
+	}
+	for _, day := range order {
+		conditions = append(conditions, Condition{
+			Day:       day,
+			Count:     days[day],
+			IssueType: feeRecipientMismatch,
+		})
+	}
+	return conditions
+}
+
+const (
+	slotsPerEpoch  = 32
+	secondsPerSlot = 12 * time.Second
+)
+
+// dayEnd is the end of the beaconchain-day of a slot proposed at timestamp, the same Day as the conditions from stats
+// so the days of all daily issues line up
+func dayEnd(slot int, timestamp time.Time) time.Time {
+	slotsPerDay := beacon.EpochsPerDay * slotsPerEpoch
+	return timestamp.Add(time.Duration(slotsPerDay-slot%slotsPerDay) * secondsPerSlot)
+}
+
+// withdrawsTo is true when 0x01 or compounding 0x02 credentials end with address, 0x00 credentials can't withdraw
+// to an address at all
+func withdrawsTo(credentials, address string) bool {
+	credentials = strings.ToLower(strings.TrimPrefix(credentials, "0x"))
+	address = strings.ToLower(strings.TrimPrefix(address, "0x"))
+	return (strings.HasPrefix(credentials, "01") || strings.HasPrefix(credentials, "02")) && strings.HasSuffix(credentials, address)
+}
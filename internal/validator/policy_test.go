@@ -0,0 +1,26 @@
+package validator
+
+import "testing"
+
+func TestWithdrawsTo(t *testing.T) {
+	const address = "0x388C818CA8B9251b393131C08a736A67ccB19297"
+	tests := []struct {
+		name        string
+		credentials string
+		want        bool
+	}{
+		{name: "0x00 bls", credentials: "0x00f50428677c60f997aadeab24aabf7fceaef491c96a52b463ae91f95611cf71", want: false},
+		{name: "0x01 matching", credentials: "0x010000000000000000000000388c818ca8b9251b393131c08a736a67ccb19297", want: true},
+		{name: "0x01 other address", credentials: "0x010000000000000000000000e839a3e9efb32c6a56ab7128e51056585275506c", want: false},
+		{name: "0x02 compounding matching", credentials: "0x020000000000000000000000388c818ca8b9251b393131c08a736a67ccb19297", want: true},
+		{name: "0x02 other address", credentials: "0x020000000000000000000000e839a3e9efb32c6a56ab7128e51056585275506c", want: false},
+		{name: "0x00 ending with the address", credentials: "0x000000000000000000000000388c818ca8b9251b393131c08a736a67ccb19297", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withdrawsTo(tt.credentials, address); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
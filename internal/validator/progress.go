@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"log"
+	"time"
+)
+
+// track counts a beaconcha.in request for the progress reporter, failures are counted per validator instead
+// so they match the validators written to the errors file
+func (c *Client) track() {
+	c.requests.Add(1)
+}
+
+// ReportProgress logs how many of total validators have been checked every interval until stop is called, with the
+// throughput and an ETA measured since the start rather than assumed from the rate limit. An interval <= 0 disables it
+func (c *Client) ReportProgress(total int, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	start := time.Now()
+	checked, requests, failures := c.checked.Load(), c.requests.Load(), c.failures.Load()
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				c.logProgress(total, start, c.checked.Load()-checked, c.requests.Load()-requests, c.failures.Load()-failures)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		c.logProgress(total, start, c.checked.Load()-checked, c.requests.Load()-requests, c.failures.Load()-failures)
+	}
+}
+
+func (c *Client) logProgress(total int, start time.Time, checked, requests, failures int64) {
+	elapsed := time.Since(start)
+	perMinute := float64(checked) / elapsed.Minutes()
+	eta := "unknown"
+	if checked > 0 {
+		remaining := float64(int64(total) - checked)
+		eta = time.Duration(remaining / float64(checked) * float64(elapsed)).Round(time.Second).String()
+	}
+	var pct float64
+	if total > 0 {
+		pct = float64(checked) / float64(total) * 100
+	}
+	log.Printf("checked %d/%d validators (%.1f%%), %.1f validators/min, %d requests (%.1f per validator), %d failed, eta %s\n",
+		checked, total, pct, perMinute, requests, perRequest(requests, checked), failures, eta)
+}
+
+func perRequest(requests, checked int64) float64 {
+	if checked == 0 {
+		return 0
+	}
+	return float64(requests) / float64(checked)
+}
@@ -0,0 +1,99 @@
+package validator
+
+import (
+	"time"
+
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+// ScoreBands go from best to worst, a validator is in the first band its score reaches
+var ScoreBands = []struct {
+	Name string
+	Min  int
+}{
+	{"excellent", 90},
+	{"good", 75},
+	{"poor", 50},
+	{"critical", 0},
+}
+
+// AttestationDuties counts the attestation duties on the days ending after since, assuming one duty per epoch for
+// every day the validator has stats
+func (h *Health) AttestationDuties(since time.Time) (duties, missed int) {
+	for _, stat := range h.statsSince(since) {
+		duties += beacon.EpochsPerDay
+		missed += stat.MissedAttestations
+	}
+	return duties, missed
+}
+
+// Uptime is the percentage of attestation duties fulfilled since, nil without any stats in the window
+func (h *Health) Uptime(since time.Time) *float64 {
+	duties, missed := h.AttestationDuties(since)
+	if duties == 0 {
+		return nil
+	}
+	uptime := float64(duties-missed) / float64(duties) * 100
+	return &uptime
+}
+
+// Income is the consensus layer income in gwei over the days ending after since
+func (h *Health) Income(since time.Time) int {
+	var income int
+	for _, stat := range h.statsSince(since) {
+		income += dailyIncome(stat)
+	}
+	return income
+}
+
+// Score rates the validator from 0 to 100, starting at its uptime and losing 5 points per missed block and up to 10
+// for missed sync duties. Slashed validators score 0 and validators that aren't active_online at most 50
+func (h *Health) Score(since time.Time) int {
+	if h.Info.Data.Slashed {
+		return 0
+	}
+	score := 100.0
+	if uptime := h.Uptime(since); uptime != nil {
+		score = *uptime
+	}
+	var missedBlocks, syncDuties, missedSync int
+	for _, stat := range h.statsSince(since) {
+		missedBlocks += stat.MissedBlocks
+		syncDuties += stat.ParticipatedSync + stat.MissedSync
+		missedSync += stat.MissedSync
+	}
+	score -= float64(5 * missedBlocks)
+	if syncDuties > 0 {
+		score -= float64(missedSync) / float64(syncDuties) * 10
+	}
+	if h.Info.Data.Status != "active_online" && score > 50 {
+		score = 50
+	}
+	if score < 0 {
+		return 0
+	}
+	return int(score)
+}
+
+// ScoreBand names the band of score
+func ScoreBand(score int) string {
+	for _, band := range ScoreBands {
+		if score >= band.Min {
+			return band.Name
+		}
+	}
+	return ScoreBands[len(ScoreBands)-1].Name
+}
+
+func (h *Health) statsSince(since time.Time) []beacon.Stat {
+	if h.Stats == nil {
+		return nil
+	}
+	var stats []beacon.Stat
+	for _, stat := range h.Stats.Data {
+		if stat.DayEnd.After(since) {
+			stats = append(stats, stat)
+		}
+	}
+	return stats
+}
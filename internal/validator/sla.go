@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+// Window is a reporting period, stats are counted when their day starts within [From, Until)
+type Window struct {
+	Name  string
+	From  time.Time
+	Until time.Time
+}
+
+// Months returns the calendar months overlapping [from, until] in UTC
+func Months(from, until time.Time) []Window {
+	var windows []Window
+	month := time.Date(from.UTC().Year(), from.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	for !month.After(until) {
+		next := month.AddDate(0, 1, 0)
+		windows = append(windows, Window{Name: month.Format("2006-01"), From: month, Until: next})
+		month = next
+	}
+	return windows
+}
+
+// DateWindow covers the days from the start of from until the end of until
+func DateWindow(from, until time.Time) Window {
+	return Window{
+		Name:  fmt.Sprintf("%s..%s", from.Format(dayLayout), until.Format(dayLayout)),
+		From:  from,
+		Until: until.AddDate(0, 0, 1),
+	}
+}
+
+// SLA counts the duties of one or more validators within a window
+type SLA struct {
+	Window
+	Days               int
+	AttestationDuties  int
+	MissedAttestations int
+	ProposedBlocks     int
+	MissedBlocks       int
+	ParticipatedSync   int
+	MissedSync         int
+}
+
+// SLA counts the duties of the validator on the days within window, one attestation duty per epoch
+func (h *Health) SLA(window Window) SLA {
+	sla := SLA{Window: window}
+	if h.Stats == nil {
+		return sla
+	}
+	for _, stat := range h.Stats.Data {
+		if stat.DayStart.Before(window.From) || !stat.DayStart.Before(window.Until) {
+			continue
+		}
+		sla.Days++
+		sla.AttestationDuties += beacon.EpochsPerDay
+		sla.MissedAttestations += stat.MissedAttestations
+		sla.ProposedBlocks += stat.ProposedBlocks
+		sla.MissedBlocks += stat.MissedBlocks
+		sla.ParticipatedSync += stat.ParticipatedSync
+		sla.MissedSync += stat.MissedSync
+	}
+	return sla
+}
+
+// Add sums the duties of another SLA over the same window, Days is the most days of any validator
+func (s *SLA) Add(other SLA) {
+	if other.Days > s.Days {
+		s.Days = other.Days
+	}
+	s.AttestationDuties += other.AttestationDuties
+	s.MissedAttestations += other.MissedAttestations
+	s.ProposedBlocks += other.ProposedBlocks
+	s.MissedBlocks += other.MissedBlocks
+	s.ParticipatedSync += other.ParticipatedSync
+	s.MissedSync += other.MissedSync
+}
+
+// AttestationRate is the percentage of attestation duties fulfilled, nil without any duties
+func (s SLA) AttestationRate() *float64 {
+	return rate(s.AttestationDuties-s.MissedAttestations, s.AttestationDuties)
+}
+
+// ProposalRate is the percentage of proposals that made it on chain, nil without any proposals
+func (s SLA) ProposalRate() *float64 {
+	return rate(s.ProposedBlocks, s.ProposedBlocks+s.MissedBlocks)
+}
+
+// SyncRate is the percentage of sync committee duties participated in, nil without any sync duties
+func (s SLA) SyncRate() *float64 {
+	return rate(s.ParticipatedSync, s.ParticipatedSync+s.MissedSync)
+}
+
+func rate(done, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	r := float64(done) / float64(total) * 100
+	return &r
+}
@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xste/validator-stats/pkg/beacon"
+)
+
+func TestHealthSLA(t *testing.T) {
+	// beaconchain-days start at 12:00:23 UTC on mainnet
+	stat := func(day int, missedAttestations, missedBlocks int) beacon.Stat {
+		return beacon.Stat{
+			DayStart:           time.Date(2026, 10, day, 12, 0, 23, 0, time.UTC),
+			MissedAttestations: missedAttestations,
+			ProposedBlocks:     1,
+			MissedBlocks:       missedBlocks,
+		}
+	}
+	health := &Health{Stats: &beacon.Stats{Data: []beacon.Stat{
+		stat(1, 5, 0),
+		stat(7, 1, 1),
+		stat(8, 2, 0),
+		stat(31, 4, 0),
+	}}}
+	tests := []struct {
+		name   string
+		window Window
+		want   SLA
+	}{
+		{
+			name:   "days starting within the dates",
+			window: DateWindow(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 7, 0, 0, 0, 0, time.UTC)),
+			want:   SLA{Days: 2, AttestationDuties: 2 * beacon.EpochsPerDay, MissedAttestations: 6, ProposedBlocks: 2, MissedBlocks: 1},
+		},
+		{
+			name:   "a month",
+			window: Months(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC))[0],
+			want:   SLA{Days: 4, AttestationDuties: 4 * beacon.EpochsPerDay, MissedAttestations: 12, ProposedBlocks: 4, MissedBlocks: 1},
+		},
+		{
+			name:   "no days",
+			window: DateWindow(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := health.SLA(tt.window)
+			tt.want.Window = tt.window
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonths(t *testing.T) {
+	got := Months(time.Date(2026, 8, 20, 6, 0, 0, 0, time.UTC), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	want := []string{"2026-08", "2026-09", "2026-10"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d months %v, want %v", len(got), got, want)
+	}
+	for i, name := range want {
+		if got[i].Name != name || got[i].Until != got[i].From.AddDate(0, 1, 0) {
+			t.Errorf("month %d got %+v, want %s", i, got[i], name)
+		}
+	}
+}
@@ -18,6 +18,12 @@ const (
 	defaultBeaconBaseUrl = "https://beaconcha.in"
 	defaultRateLimit     = 10
 	defaultInterval      = 1 * time.Minute
+
+	// MaxValidatorsPerRequest is the most pubkeys or indices the multi validator endpoints accept
+	MaxValidatorsPerRequest = 100
+
+	// EpochsPerDay is the length of a beaconchain-day, (24 * 60 * 60) / 32 slots / 12 seconds
+	EpochsPerDay = 225
 )
 
 type Client struct {
@@ -79,24 +85,21 @@ type Validator struct {
 
 func (c *Client) GetValidator(ctx context.Context, pubkeys ...string) (*Validator, error) {
 	c.rl.Wait(ctx)
+	path := fmt.Sprintf("/api/v1/validator/%s", delimit(pubkeys, ","))
 	resp, err := c.rc.R().
 		SetContext(ctx).
-		Get(fmt.Sprintf("/api/v1/validator/%s", delimit(pubkeys, ",")))
+		Get(path)
 	if err != nil {
 		return nil, err
 	}
 	if resp.StatusCode() == http.StatusTooManyRequests {
 		c.rl.Wait(ctx)
-		return nil, fmt.Errorf("rate_limit_exceeded")
 	}
 	if resp.StatusCode() == http.StatusBadRequest && bytes.Contains(resp.Body(), []byte("pubkey(s) did not resolve to a validator")) {
-		return nil, fmt.Errorf("pubkey '%s' not found", pubkeys)
-	}
-	if resp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("response was %d", resp.StatusCode())
+		return nil, &NotFoundError{Pubkeys: pubkeys}
 	}
 	var validator Validator
-	if err := json.Unmarshal(resp.Body(), &validator); err != nil {
+	if err := decode(resp, path, &validator); err != nil {
 		return nil, err
 	}
 	return &validator, nil
@@ -147,73 +150,227 @@ type Proposals struct {
 func (c *Client) GetValidatorProposals(ctx context.Context, epoch string, pubkeys ...string) (*Proposals, error) {
 	c.rl.Wait(ctx)
 	delimited := delimit(pubkeys, ",")
+	path := fmt.Sprintf("/api/v1/validator/%s/proposals", delimited)
 	resp, err := c.rc.R().
 		SetContext(ctx).
 		//SetQueryParam("epoch", epoch).
-		Get(fmt.Sprintf("/api/v1/validator/%s/proposals", delimited))
+		Get(path)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("response was %d for %s", resp.StatusCode(), pubkeys)
-	}
 	var proposals Proposals
-	if err := json.Unmarshal(resp.Body(), &proposals); err != nil {
+	if err := decode(resp, path, &proposals); err != nil {
 		return nil, err
 	}
 	return &proposals, nil
 }
 
 type Stats struct {
-	Data []struct {
-		AttesterSlashings     int       `json:"attester_slashings"`
-		Day                   int       `json:"day"`
-		DayEnd                time.Time `json:"day_end"`
-		DayStart              time.Time `json:"day_start"`
-		Deposits              int       `json:"deposits"`
-		DepositsAmount        int       `json:"deposits_amount"`
-		EndBalance            int       `json:"end_balance"`
-		EndEffectiveBalance   int       `json:"end_effective_balance"`
-		MaxBalance            int       `json:"max_balance"`
-		MaxEffectiveBalance   int       `json:"max_effective_balance"`
-		MinBalance            int       `json:"min_balance"`
-		MinEffectiveBalance   int       `json:"min_effective_balance"`
-		MissedAttestations    int       `json:"missed_attestations"`
-		MissedBlocks          int       `json:"missed_blocks"`
-		MissedSync            int       `json:"missed_sync"`
-		OrphanedAttestations  int       `json:"orphaned_attestations"`
-		OrphanedBlocks        int       `json:"orphaned_blocks"`
-		OrphanedSync          int       `json:"orphaned_sync"`
-		ParticipatedSync      int       `json:"participated_sync"`
-		ProposedBlocks        int       `json:"proposed_blocks"`
-		ProposerSlashings     int       `json:"proposer_slashings"`
-		StartBalance          int       `json:"start_balance"`
-		StartEffectiveBalance int       `json:"start_effective_balance"`
-		Validatorindex        int       `json:"validatorindex"`
-		Withdrawals           int       `json:"withdrawals"`
-		WithdrawalsAmount     int       `json:"withdrawals_amount"`
-	} `json:"data"`
+	Data   []Stat `json:"data"`
 	Status string `json:"status"`
 }
 
+type Stat struct {
+	AttesterSlashings     int       `json:"attester_slashings"`
+	Day                   int       `json:"day"`
+	DayEnd                time.Time `json:"day_end"`
+	DayStart              time.Time `json:"day_start"`
+	Deposits              int       `json:"deposits"`
+	DepositsAmount        int       `json:"deposits_amount"`
+	EndBalance            int       `json:"end_balance"`
+	EndEffectiveBalance   int       `json:"end_effective_balance"`
+	MaxBalance            int       `json:"max_balance"`
+	MaxEffectiveBalance   int       `json:"max_effective_balance"`
+	MinBalance            int       `json:"min_balance"`
+	MinEffectiveBalance   int       `json:"min_effective_balance"`
+	MissedAttestations    int       `json:"missed_attestations"`
+	MissedBlocks          int       `json:"missed_blocks"`
+	MissedSync            int       `json:"missed_sync"`
+	OrphanedAttestations  int       `json:"orphaned_attestations"`
+	OrphanedBlocks        int       `json:"orphaned_blocks"`
+	OrphanedSync          int       `json:"orphaned_sync"`
+	ParticipatedSync      int       `json:"participated_sync"`
+	ProposedBlocks        int       `json:"proposed_blocks"`
+	ProposerSlashings     int       `json:"proposer_slashings"`
+	StartBalance          int       `json:"start_balance"`
+	StartEffectiveBalance int       `json:"start_effective_balance"`
+	Validatorindex        int       `json:"validatorindex"`
+	Withdrawals           int       `json:"withdrawals"`
+	WithdrawalsAmount     int       `json:"withdrawals_amount"`
+}
+
 func (c *Client) GetValidatorStats(ctx context.Context, days int, index int) (*Stats, error) {
 	c.rl.Wait(ctx)
+	path := fmt.Sprintf("/api/v1/validator/stats/%d", index)
 	resp, err := c.rc.R().
 		SetContext(ctx).
-		Get(fmt.Sprintf("/api/v1/validator/stats/%d", index))
+		Get(path)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("response was %d", resp.StatusCode())
-	}
 	var proposals Stats
-	if err := json.Unmarshal(resp.Body(), &proposals); err != nil {
+	if err := decode(resp, path, &proposals); err != nil {
 		return nil, err
 	}
 	return &proposals, nil
 }
 
+type AttestationEffectiveness struct {
+	Data []struct {
+		AttestationEffectiveness float64 `json:"attestationeffectiveness"`
+		Validatorindex           int     `json:"validatorindex"`
+	} `json:"data"`
+	Status string `json:"status"`
+}
+
+// GetAttestationEffectiveness returns the attestation effectiveness percentage of up to 100 validators
+func (c *Client) GetAttestationEffectiveness(ctx context.Context, pubkeys ...string) (*AttestationEffectiveness, error) {
+	c.rl.Wait(ctx)
+	path := fmt.Sprintf("/api/v1/validator/%s/attestationeffectiveness", delimit(pubkeys, ","))
+	resp, err := c.rc.R().
+		SetContext(ctx).
+		Get(path)
+	if err != nil {
+		return nil, err
+	}
+	var effectiveness AttestationEffectiveness
+	if err := decode(resp, path, &effectiveness); err != nil {
+		return nil, err
+	}
+	return &effectiveness, nil
+}
+
+type AttestationEfficiency struct {
+	Data []struct {
+		AttestationEfficiency float64 `json:"attestation_efficiency"`
+		Validatorindex        int     `json:"validatorindex"`
+	} `json:"data"`
+	Status string `json:"status"`
+}
+
+// GetAttestationEfficiency returns the inclusion delay based attestation efficiency of up to 100 validators, 1 is optimal
+func (c *Client) GetAttestationEfficiency(ctx context.Context, pubkeys ...string) (*AttestationEfficiency, error) {
+	c.rl.Wait(ctx)
+	path := fmt.Sprintf("/api/v1/validator/%s/attestationefficiency", delimit(pubkeys, ","))
+	resp, err := c.rc.R().
+		SetContext(ctx).
+		Get(path)
+	if err != nil {
+		return nil, err
+	}
+	var efficiency AttestationEfficiency
+	if err := decode(resp, path, &efficiency); err != nil {
+		return nil, err
+	}
+	return &efficiency, nil
+}
+
+type Attestations struct {
+	Data   []Attestation `json:"data"`
+	Status string        `json:"status"`
+}
+
+type Attestation struct {
+	AttesterSlot   int `json:"attesterslot"`
+	CommitteeIndex int `json:"committeeindex"`
+	Epoch          int `json:"epoch"`
+	InclusionSlot  int `json:"inclusionslot"`
+	Status         int `json:"status"`
+	Validatorindex int `json:"validatorindex"`
+}
+
+// attestation statuses of beaconcha.in, duties of the current epochs stay scheduled until they are included or missed
+const (
+	AttestationScheduled = 0
+	AttestationAttested  = 1
+	AttestationMissed    = 2
+)
+
+// HeadEpoch is the latest epoch with a duty in the response, zero without duties
+func (a *Attestations) HeadEpoch() int {
+	head := 0
+	for _, attestation := range a.Data {
+		if attestation.Epoch > head {
+			head = attestation.Epoch
+		}
+	}
+	return head
+}
+
+// Missed is true when beaconcha.in marked the attestation missed and it can no longer be included,
+// an attestation of epoch N can be included until the end of epoch N+1
+func (a Attestation) Missed(head int) bool {
+	return a.Status == AttestationMissed && head > a.Epoch+1
+}
+
+// GetValidatorAttestations returns the attestation duties of the last 10 epochs for up to 100 validators,
+// older epochs are not served by beaconcha.in
+func (c *Client) GetValidatorAttestations(ctx context.Context, pubkeys ...string) (*Attestations, error) {
+	c.rl.Wait(ctx)
+	path := fmt.Sprintf("/api/v1/validator/%s/attestations", delimit(pubkeys, ","))
+	resp, err := c.rc.R().
+		SetContext(ctx).
+		Get(path)
+	if err != nil {
+		return nil, err
+	}
+	var attestations Attestations
+	if err := decode(resp, path, &attestations); err != nil {
+		return nil, err
+	}
+	return &attestations, nil
+}
+
+type EthStore struct {
+	Data struct {
+		Apr   float64 `json:"apr"`
+		ClApr float64 `json:"cl_apr"`
+		ElApr float64 `json:"el_apr"`
+		// averages over the 7 and 31 days ending with Day, zero when beaconcha.in doesn't have them
+		AvgApr7d    float64   `json:"avgapr7d"`
+		AvgApr31d   float64   `json:"avgapr31d"`
+		AvgClApr7d  float64   `json:"avgclapr7d"`
+		AvgClApr31d float64   `json:"avgclapr31d"`
+		Day         int       `json:"day"`
+		DayEnd      time.Time `json:"day_end"`
+		DayStart    time.Time `json:"day_start"`
+		Validators  int       `json:"validators"`
+	} `json:"data"`
+	Status string `json:"status"`
+}
+
+// GetEthStore returns the ETH.STORE reference rate for a beaconchain-day, day can also be "latest"
+func (c *Client) GetEthStore(ctx context.Context, day string) (*EthStore, error) {
+	c.rl.Wait(ctx)
+	path := fmt.Sprintf("/api/v1/ethstore/%s", day)
+	resp, err := c.rc.R().
+		SetContext(ctx).
+		Get(path)
+	if err != nil {
+		return nil, err
+	}
+	var ethStore EthStore
+	if err := decode(resp, path, &ethStore); err != nil {
+		return nil, err
+	}
+	return &ethStore, nil
+}
+
+// decode unmarshals the body of a 200 OK response into v
+func decode(resp *resty.Response, path string, v any) error {
+	switch resp.StatusCode() {
+	case http.StatusOK:
+	case http.StatusTooManyRequests:
+		return &RateLimitError{Path: path}
+	default:
+		return &StatusError{Path: path, Code: resp.StatusCode()}
+	}
+	if err := json.Unmarshal(resp.Body(), v); err != nil {
+		return &DecodeError{Path: path, Err: err}
+	}
+	return nil
+}
+
 func (c *Client) GetInterval() time.Duration {
 	return c.interval
 }
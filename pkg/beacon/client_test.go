@@ -0,0 +1,36 @@
+package beacon
+
+import "testing"
+
+func TestAttestationMissed(t *testing.T) {
+	tests := []struct {
+		name        string
+		attestation Attestation
+		head        int
+		want        bool
+	}{
+		{name: "attested", attestation: Attestation{Epoch: 100, Status: AttestationAttested}, head: 110, want: false},
+		{name: "scheduled in the head epoch", attestation: Attestation{Epoch: 110, Status: AttestationScheduled}, head: 110, want: false},
+		{name: "scheduled in an old epoch", attestation: Attestation{Epoch: 100, Status: AttestationScheduled}, head: 110, want: false},
+		{name: "missed in the head epoch", attestation: Attestation{Epoch: 110, Status: AttestationMissed}, head: 110, want: false},
+		{name: "missed but still includable next epoch", attestation: Attestation{Epoch: 109, Status: AttestationMissed}, head: 110, want: false},
+		{name: "missed after the inclusion window", attestation: Attestation{Epoch: 108, Status: AttestationMissed}, head: 110, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.attestation.Missed(tt.head); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeadEpoch(t *testing.T) {
+	attestations := &Attestations{Data: []Attestation{{Epoch: 101}, {Epoch: 110}, {Epoch: 105}}}
+	if got := attestations.HeadEpoch(); got != 110 {
+		t.Errorf("got %d, want 110", got)
+	}
+	if got := (&Attestations{}).HeadEpoch(); got != 0 {
+		t.Errorf("got %d without duties, want 0", got)
+	}
+}
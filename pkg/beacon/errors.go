@@ -0,0 +1,69 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NotFoundError is returned when beaconcha.in doesn't resolve the pubkeys to a validator
+type NotFoundError struct {
+	Pubkeys []string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("pubkey '%s' not found", delimit(e.Pubkeys, ","))
+}
+
+// RateLimitError is returned when beaconcha.in responds 429 despite the client side rate limit
+type RateLimitError struct {
+	Path string
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s", e.Path)
+}
+
+// StatusError is returned for any other unexpected response status
+type StatusError struct {
+	Path string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("response was %d for %s", e.Code, e.Path)
+}
+
+// DecodeError is returned when the response body isn't the expected json
+type DecodeError struct {
+	Path string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode %s: %s", e.Path, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Reason classifies an error of the client, request covers transport errors such as timeouts
+func Reason(err error) string {
+	var notFound *NotFoundError
+	var rateLimit *RateLimitError
+	var status *StatusError
+	var decode *DecodeError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &notFound):
+		return "not_found"
+	case errors.As(err, &rateLimit):
+		return "rate_limited"
+	case errors.As(err, &status):
+		return "http_status"
+	case errors.As(err, &decode):
+		return "decode"
+	}
+	return "request"
+}
@@ -1,11 +1,15 @@
 package prom
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/api"
 	"github.com/prometheus/common/config"
 )
@@ -14,8 +18,17 @@ type Client struct {
 	c            api.Client
 	roundTripper http.RoundTripper
 	address      *string
-	auth         bool
 	timeout      time.Duration
+	discovery    Discovery
+
+	// auth
+	username    string
+	password    config.Secret
+	bearerToken config.Secret
+	certFile    string
+	keyFile     string
+	caFile      string
+	headers     map[string]string
 }
 
 type ErrInvalidPromClientConfig struct {
@@ -28,18 +41,44 @@ func (e *ErrInvalidPromClientConfig) Error() string {
 
 func New(options ...func(c *Client)) (*Client, error) {
 	client := &Client{
-		timeout: time.Second * 10,
+		timeout:   time.Second * 10,
+		discovery: presets["prysm"],
+		headers:   make(map[string]string),
 	}
 	for _, option := range options {
 		option(client)
 	}
 
-	if !client.auth {
-		return nil, &ErrInvalidPromClientConfig{Field: "basicAuth"}
-	}
 	if client.address == nil {
 		return nil, &ErrInvalidPromClientConfig{Field: "address"}
 	}
+	if client.discovery.Query == "" || client.discovery.PubkeyLabel == "" {
+		return nil, &ErrInvalidPromClientConfig{Field: "discovery"}
+	}
+	// a password without a user would otherwise be dropped and the requests sent unauthenticated
+	if client.username == "" && client.password != "" {
+		return nil, &ErrInvalidPromClientConfig{Field: "username"}
+	}
+	if client.username != "" && client.bearerToken != "" {
+		return nil, &ErrInvalidPromClientConfig{Field: "auth"}
+	}
+	if (client.certFile == "") != (client.keyFile == "") {
+		return nil, &ErrInvalidPromClientConfig{Field: "clientCert"}
+	}
+
+	transport, err := client.transport()
+	if err != nil {
+		return nil, err
+	}
+	client.roundTripper = &roundTripper{
+		userAgent:   "github.com/0xste/validator-stats",
+		username:    client.username,
+		password:    client.password,
+		bearerToken: client.bearerToken,
+		headers:     client.headers,
+		rt:          transport,
+	}
+
 	c, err := api.NewClient(api.Config{
 		Address:      *client.address,
 		RoundTripper: client.roundTripper,
@@ -51,51 +90,106 @@ func New(options ...func(c *Client)) (*Client, error) {
 	return client, nil
 }
 
+// transport applies the mTLS config on top of the default prometheus round tripper
+func (c *Client) transport() (http.RoundTripper, error) {
+	if c.certFile == "" && c.caFile == "" {
+		return api.DefaultRoundTripper, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load client cert")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if c.caFile != "" {
+		ca, err := os.ReadFile(c.caFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read ca file")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, &ErrInvalidPromClientConfig{Field: "ca"}
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := api.DefaultRoundTripper.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
 func WithAddress(address string) func(reconfigure *Client) {
 	return func(c *Client) {
 		c.address = &address
 	}
 }
 
+func WithDiscovery(discovery Discovery) func(reconfigure *Client) {
+	return func(c *Client) {
+		c.discovery = discovery
+	}
+}
+
 func WithBasicAuth(user, pass string) func(reconfigure *Client) {
 	return func(c *Client) {
-		if user != "" && pass != "" {
-			c.auth = true
-		}
-		c.roundTripper = &roundTripper{
-			userAgent: "github.com/0xste/validator-stats",
-			username:  user,
-			password:  config.Secret(pass),
-			rt:        api.DefaultRoundTripper,
-		}
+		c.username = user
+		c.password = config.Secret(pass)
+	}
+}
+
+func WithBearerToken(token string) func(reconfigure *Client) {
+	return func(c *Client) {
+		c.bearerToken = config.Secret(token)
+	}
+}
+
+// WithClientCert authenticates with a client certificate, the files are PEM encoded
+func WithClientCert(certFile, keyFile string) func(reconfigure *Client) {
+	return func(c *Client) {
+		c.certFile = certFile
+		c.keyFile = keyFile
+	}
+}
+
+// WithCA verifies the server with a PEM encoded CA instead of the system roots
+func WithCA(caFile string) func(reconfigure *Client) {
+	return func(c *Client) {
+		c.caFile = caFile
+	}
+}
+
+// WithHeader sets a header on every request, e.g. a tenant id for a hosted prometheus
+func WithHeader(name, value string) func(reconfigure *Client) {
+	return func(c *Client) {
+		c.headers[name] = value
 	}
 }
 
 type roundTripper struct {
-	userAgent string
-	username  string
-	password  config.Secret
-	rt        http.RoundTripper
+	userAgent   string
+	username    string
+	password    config.Secret
+	bearerToken config.Secret
+	headers     map[string]string
+	rt          http.RoundTripper
 }
 
 func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	if len(req.Header.Get("Authorization")) != 0 {
-		return rt.rt.RoundTrip(req)
-	}
 	req = cloneRequest(req)
-	req.SetBasicAuth(rt.username, strings.TrimSpace(string(rt.password)))
-	if req.UserAgent() == "" {
-		// The specification of http.RoundTripper says that it shouldn't mutate
-		// the request so make a copy of req.Header since this is all that is
-		// modified.
-		r2 := new(http.Request)
-		*r2 = *req
-		r2.Header = make(http.Header)
-		for k, s := range req.Header {
-			r2.Header[k] = s
+	for name, value := range rt.headers {
+		req.Header.Set(name, value)
+	}
+	if len(req.Header.Get("Authorization")) == 0 {
+		switch {
+		case rt.username != "":
+			req.SetBasicAuth(rt.username, strings.TrimSpace(string(rt.password)))
+		case rt.bearerToken != "":
+			req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(rt.bearerToken)))
 		}
-		r2.Header.Set("User-Agent", rt.userAgent)
-		req = r2
+	}
+	if req.UserAgent() == "" {
+		req.Header.Set("User-Agent", rt.userAgent)
 	}
 
 	return rt.rt.RoundTrip(req)
@@ -0,0 +1,72 @@
+package prom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Discovery describes how validator pubkeys are found in prometheus
+type Discovery struct {
+	// Query is the PromQL returning one series per validator, %s is replaced with the label selector
+	Query string
+	// PubkeyLabel is the label holding the pubkey, beaconcha.in also accepts a validator index
+	PubkeyLabel string
+	// NetworkLabel and Network filter the series to a single network, no filter is applied when Network is empty
+	NetworkLabel string
+	Network      string
+}
+
+// presets are the per validator metrics exported by each client, override the query when they are relabelled.
+// Clients without a series labelled by full pubkey or index leave Query empty and need PROM_QUERY
+var presets = map[string]Discovery{
+	// validator client, node_network isn't exported by prysm but added by the relabelling the original query relied on
+	"prysm": {
+		Query:        `validator_statuses{%s} != 0`, // don't check inactive validators
+		PubkeyLabel:  "pubkey",
+		NetworkLabel: "node_network",
+		Network:      "mainnet",
+	},
+	// beacon node validator monitor, individual series are only exported up to --validator-monitor-individual-tracking-threshold
+	"lighthouse": {
+		Query:       `validator_monitor_balance_gwei{%s}`,
+		PubkeyLabel: "validator",
+	},
+	// teku only exports aggregate validator metrics e.g. validator_local_validator_counts
+	"teku": {},
+	// the nimbus validator monitor labels its series with a shortened pubkey beaconcha.in can't resolve
+	"nimbus": {},
+	// beacon node validator monitor, series are labelled by validator index
+	"lodestar": {
+		Query:       `validator_monitor_prev_epoch_on_chain_balance{%s}`,
+		PubkeyLabel: "index",
+	},
+}
+
+// Preset returns a copy of the discovery of a client
+func Preset(name string) (Discovery, bool) {
+	discovery, ok := presets[name]
+	return discovery, ok
+}
+
+// PresetNames lists the available presets in a stable order
+func PresetNames() []string {
+	var names []string
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PromQL renders the discovery query with the pubkey and network selector
+func (d Discovery) PromQL() string {
+	selectors := []string{fmt.Sprintf(`%s!=""`, d.PubkeyLabel)}
+	if d.Network != "" && d.NetworkLabel != "" {
+		selectors = append(selectors, fmt.Sprintf(`%s=%q`, d.NetworkLabel, d.Network))
+	}
+	if !strings.Contains(d.Query, "%s") {
+		return d.Query
+	}
+	return fmt.Sprintf(d.Query, strings.Join(selectors, ", "))
+}
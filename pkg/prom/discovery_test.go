@@ -0,0 +1,49 @@
+package prom
+
+import "testing"
+
+func TestDiscoveryPromQL(t *testing.T) {
+	tests := []struct {
+		name      string
+		discovery Discovery
+		want      string
+	}{
+		{
+			name:      "prysm preset",
+			discovery: presets["prysm"],
+			want:      `validator_statuses{pubkey!="", node_network="mainnet"} != 0`,
+		},
+		{
+			name:      "lighthouse preset without a network",
+			discovery: presets["lighthouse"],
+			want:      `validator_monitor_balance_gwei{validator!=""}`,
+		},
+		{
+			name:      "lodestar preset on holesky",
+			discovery: Discovery{Query: presets["lodestar"].Query, PubkeyLabel: "index", NetworkLabel: "network", Network: "holesky"},
+			want:      `validator_monitor_prev_epoch_on_chain_balance{index!="", network="holesky"}`,
+		},
+		{
+			name:      "network without a label isn't filtered",
+			discovery: Discovery{Query: `up{%s}`, PubkeyLabel: "pubkey", Network: "mainnet"},
+			want:      `up{pubkey!=""}`,
+		},
+		{
+			name:      "network value is quoted",
+			discovery: Discovery{Query: `up{%s}`, PubkeyLabel: "pubkey", NetworkLabel: "net", Network: `a"b`},
+			want:      `up{pubkey!="", net="a\"b"}`,
+		},
+		{
+			name:      "query without a placeholder is used as is",
+			discovery: Discovery{Query: `my_validators`, PubkeyLabel: "pubkey", NetworkLabel: "net", Network: "mainnet"},
+			want:      `my_validators`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.discovery.PromQL(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package prom
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// Probe is a local metric checked over the window of a condition to see if our own node saw trouble
+type Probe struct {
+	Name string
+	// Query is a text/template rendered with the .Pubkey, .Index and .Labels of the validator
+	Query string
+	// Trouble is how the series points to a local problem, one of TroubleIncrease, TroubleDecrease, TroubleBelow or TroubleAbove
+	Trouble   string
+	Threshold float64
+	// Issues are the issue types the probe corroborates, any issue when empty
+	Issues []string
+}
+
+const (
+	TroubleIncrease = "increase"
+	TroubleDecrease = "decrease"
+	TroubleBelow    = "below"
+	TroubleAbove    = "above"
+)
+
+// probes are the local metrics checked for each preset, the peer count is taken from the validator's instance.
+// Teku and nimbus don't export a series per validator pubkey so only their peer count is checked
+var probes = map[string][]Probe{
+	"prysm": {
+		{Name: "failed_attestations", Query: `sum(validator_failed_attestations{pubkey="{{.Pubkey}}"})`, Trouble: TroubleIncrease, Issues: []string{"missed_attestation"}},
+		{Name: "balance", Query: `validator_balance{pubkey="{{.Pubkey}}"}`, Trouble: TroubleDecrease},
+		{Name: "peers", Query: `sum(p2p_peer_count{state="Connected", instance="{{.Labels.instance}}"})`, Trouble: TroubleBelow, Threshold: 10},
+	},
+	"lighthouse": {
+		{Name: "attester_misses", Query: `sum(validator_monitor_prev_epoch_on_chain_attester_miss{validator="{{.Pubkey}}"})`, Trouble: TroubleIncrease, Issues: []string{"missed_attestation"}},
+		{Name: "balance", Query: `validator_monitor_balance_gwei{validator="{{.Pubkey}}"}`, Trouble: TroubleDecrease},
+		{Name: "peers", Query: `sum(libp2p_peers{instance="{{.Labels.instance}}"})`, Trouble: TroubleBelow, Threshold: 10},
+	},
+	"teku": {
+		{Name: "peers", Query: `sum(beacon_peer_count{instance="{{.Labels.instance}}"})`, Trouble: TroubleBelow, Threshold: 10},
+	},
+	"nimbus": {
+		{Name: "peers", Query: `sum(nbc_peers{instance="{{.Labels.instance}}"})`, Trouble: TroubleBelow, Threshold: 10},
+	},
+	"lodestar": {
+		{Name: "attester_misses", Query: `sum(validator_monitor_prev_epoch_on_chain_attester_miss_total{index="{{.Index}}"})`, Trouble: TroubleIncrease, Issues: []string{"missed_attestation"}},
+		{Name: "balance", Query: `validator_monitor_prev_epoch_on_chain_balance{index="{{.Index}}"}`, Trouble: TroubleDecrease},
+		{Name: "peers", Query: `sum(libp2p_peers{instance="{{.Labels.instance}}"})`, Trouble: TroubleBelow, Threshold: 10},
+	},
+}
+
+// ProbesFor returns a copy of the probes of a preset, nil for an unknown preset
+func ProbesFor(preset string) []Probe {
+	return append([]Probe(nil), probes[preset]...)
+}
+
+// pubkeyPattern is a hex encoded BLS pubkey, the only pubkey format interpolated into a probe
+var pubkeyPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{96}$`)
+
+// escapeLabelValue quotes a value for a double quoted PromQL string, which follows the Go escaping rules
+func escapeLabelValue(value string) string {
+	quoted := strconv.Quote(value)
+	return quoted[1 : len(quoted)-1]
+}
+
+// Observation summarises a probe over a window
+type Observation struct {
+	Query   string
+	Samples int
+	First   float64
+	Last    float64
+	Min     float64
+	Max     float64
+	// Trouble is only meaningful when there are samples
+	Trouble bool
+}
+
+// Observe runs the probe for a validator between start and end, labels are escaped before they are rendered into the query
+func (c *Client) Observe(ctx context.Context, probe Probe, pubkey string, index int, labels map[string]string, start, end time.Time) (*Observation, error) {
+	if !pubkeyPattern.MatchString(pubkey) {
+		return nil, fmt.Errorf("probe %s: invalid pubkey %q", probe.Name, pubkey)
+	}
+	escaped := make(map[string]string, len(labels))
+	for name, value := range labels {
+		escaped[name] = escapeLabelValue(value)
+	}
+	tmpl, err := template.New(probe.Name).Option("missingkey=zero").Parse(probe.Query)
+	if err != nil {
+		return nil, err
+	}
+	var query bytes.Buffer
+	if err := tmpl.Execute(&query, struct {
+		Pubkey string
+		Index  int
+		Labels map[string]string
+	}{pubkey, index, escaped}); err != nil {
+		return nil, err
+	}
+
+	observation := &Observation{Query: query.String()}
+	resp, err := c.QueryRange(ctx, observation.Query, start, end, 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	matrix, ok := resp.(model.Matrix)
+	if !ok {
+		return nil, fmt.Errorf("probe %s returned %s, expected a matrix", probe.Name, resp.Type())
+	}
+	if len(matrix) == 0 || len(matrix[0].Values) == 0 {
+		return observation, nil
+	}
+
+	values := matrix[0].Values
+	observation.Samples = len(values)
+	observation.First = float64(values[0].Value)
+	observation.Last = float64(values[len(values)-1].Value)
+	observation.Min, observation.Max = observation.First, observation.First
+	for _, value := range values {
+		if float64(value.Value) < observation.Min {
+			observation.Min = float64(value.Value)
+		}
+		if float64(value.Value) > observation.Max {
+			observation.Max = float64(value.Value)
+		}
+	}
+
+	switch probe.Trouble {
+	case TroubleIncrease:
+		// counters reset on restart, which is trouble in its own right
+		observation.Trouble = observation.Last > observation.First || observation.Min < observation.First
+	case TroubleDecrease:
+		observation.Trouble = observation.Last < observation.First
+	case TroubleBelow:
+		observation.Trouble = observation.Min < probe.Threshold
+	case TroubleAbove:
+		observation.Trouble = observation.Max > probe.Threshold
+	}
+	return observation, nil
+}
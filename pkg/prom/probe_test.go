@@ -0,0 +1,89 @@
+package prom
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscapeLabelValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "plain", value: "node-1:9090", want: "node-1:9090"},
+		{name: "double quote", value: `a"} or vector(1) or {a="`, want: `a\"} or vector(1) or {a=\"`},
+		{name: "backslash", value: `c:\metrics`, want: `c:\\metrics`},
+		{name: "newline", value: "a\nb", want: `a\nb`},
+		{name: "unicode is kept", value: "nœud", want: "nœud"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeLabelValue(tt.value); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPubkeyPattern(t *testing.T) {
+	pubkey := "0x" + strings.Repeat("aB", 48)
+	tests := []struct {
+		name   string
+		pubkey string
+		want   bool
+	}{
+		{name: "pubkey", pubkey: pubkey, want: true},
+		{name: "no prefix", pubkey: strings.TrimPrefix(pubkey, "0x"), want: false},
+		{name: "too short", pubkey: pubkey[:len(pubkey)-2], want: false},
+		{name: "too long", pubkey: pubkey + "aa", want: false},
+		{name: "not hex", pubkey: pubkey[:len(pubkey)-1] + "g", want: false},
+		{name: "injection", pubkey: pubkey + `"} or vector(1)`, want: false},
+		{name: "trailing newline", pubkey: pubkey + "\n", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pubkeyPattern.MatchString(tt.pubkey); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObserveEscapesLabels(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.FormValue("query")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+	}))
+	defer server.Close()
+
+	client, err := New(WithAddress(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	probe := Probe{Name: "peers", Query: `sum(libp2p_peers{instance="{{.Labels.instance}}"})`, Trouble: TroubleBelow, Threshold: 10}
+	pubkey := "0x" + strings.Repeat("ab", 48)
+	end := time.Now()
+
+	observation, err := client.Observe(context.Background(), probe, pubkey, 1, map[string]string{"instance": `x"} or vector(1) or {a="`}, end.Add(-24*time.Hour), end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `sum(libp2p_peers{instance="x\"} or vector(1) or {a=\""})`
+	if query != want || observation.Query != want {
+		t.Errorf("got query %s, want %s", query, want)
+	}
+	if observation.Samples != 0 || observation.Trouble {
+		t.Errorf("got %+v, want no samples and no trouble", observation)
+	}
+
+	if _, err := client.Observe(context.Background(), probe, pubkey+`"}`, 1, nil, end.Add(-24*time.Hour), end); err == nil {
+		t.Error("expected an error for an invalid pubkey")
+	}
+}
@@ -0,0 +1,88 @@
+package prom
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// Push replaces the metrics of job on the Pushgateway at the client address
+func (c *Client) Push(ctx context.Context, job string, g prometheus.Gatherer) error {
+	return push.New(*c.address, job).
+		Client(&http.Client{Transport: c.roundTripper, Timeout: c.timeout}).
+		Gatherer(g).
+		PushContext(ctx)
+}
+
+// RemoteWrite sends the gathered gauges, counters and untyped metrics to the remote-write endpoint at the client address
+func (c *Client) RemoteWrite(ctx context.Context, g prometheus.Gatherer) error {
+	families, err := g.Gather()
+	if err != nil {
+		return err
+	}
+	request, err := writeRequest(families, time.Now()).Marshal()
+	if err != nil {
+		return err
+	}
+	body := snappy.Encode(nil, request)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, *c.address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	resp, err := c.roundTripper.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("remote write response was %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// writeRequest converts the gathered families to a remote-write request, every sample is stamped with now
+func writeRequest(families []*dto.MetricFamily, now time.Time) *prompb.WriteRequest {
+	request := &prompb.WriteRequest{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			var value float64
+			switch family.GetType() {
+			case dto.MetricType_GAUGE:
+				value = metric.GetGauge().GetValue()
+			case dto.MetricType_COUNTER:
+				value = metric.GetCounter().GetValue()
+			case dto.MetricType_UNTYPED:
+				value = metric.GetUntyped().GetValue()
+			default:
+				continue
+			}
+
+			labels := []prompb.Label{{Name: "__name__", Value: family.GetName()}}
+			for _, label := range metric.GetLabel() {
+				labels = append(labels, prompb.Label{Name: label.GetName(), Value: label.GetValue()})
+			}
+			// remote-write receivers require the labels of a series sorted by name
+			sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+			request.Timeseries = append(request.Timeseries, prompb.TimeSeries{
+				Labels:  labels,
+				Samples: []prompb.Sample{{Value: value, Timestamp: now.UnixMilli()}},
+			})
+		}
+	}
+	return request
+}
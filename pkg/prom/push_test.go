@@ -0,0 +1,167 @@
+package prom
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// testGatherer has a gauge per validator and a long label value
+func testGatherer(t *testing.T) prometheus.Gatherer {
+	t.Helper()
+	balance := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "validator_health_balance_gwei", Help: "Balance."}, []string{"pubkey", "group"})
+	balance.WithLabelValues("0xaa", "client-a").Set(32000000000)
+	balance.WithLabelValues("0xbb", strings.Repeat("b", 70000)).Set(31.5)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(balance)
+	return registry
+}
+
+func TestPush(t *testing.T) {
+	var method, path, auth string
+	var families []*dto.MetricFamily
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, auth = r.Method, r.URL.Path, r.Header.Get("Authorization")
+		decoder := expfmt.NewDecoder(r.Body, expfmt.ResponseFormat(r.Header))
+		for {
+			family := &dto.MetricFamily{}
+			if err := decoder.Decode(family); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("failed to decode push: %s", err)
+				break
+			}
+			families = append(families, family)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := New(WithAddress(server.URL), WithBearerToken("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Push(context.Background(), "validator-health", testGatherer(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if method != http.MethodPut || path != "/metrics/job/validator-health" {
+		t.Errorf("got %s %s, want PUT /metrics/job/validator-health", method, path)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("got Authorization %q, want the bearer token", auth)
+	}
+	if len(families) != 1 || families[0].GetName() != "validator_health_balance_gwei" {
+		t.Fatalf("got families %v, want validator_health_balance_gwei", families)
+	}
+	got := make(map[string]float64)
+	for _, metric := range families[0].GetMetric() {
+		labels := make(map[string]string)
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		got[labels["pubkey"]+"/"+labels["group"]] = metric.GetGauge().GetValue()
+	}
+	want := map[string]float64{"0xaa/client-a": 32000000000, "0xbb/" + strings.Repeat("b", 70000): 31.5}
+	if len(got) != len(want) {
+		t.Fatalf("got %d series, want %d", len(got), len(want))
+	}
+	for series, value := range want {
+		if got[series] != value {
+			t.Errorf("got %v for %.20s, want %v", got[series], series, value)
+		}
+	}
+}
+
+func TestRemoteWrite(t *testing.T) {
+	var headers http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := New(WithAddress(server.URL), WithBasicAuth("user", "pass"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.RemoteWrite(context.Background(), testGatherer(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if headers.Get("Content-Encoding") != "snappy" || headers.Get("Content-Type") != "application/x-protobuf" {
+		t.Errorf("got headers %v, want a snappy protobuf", headers)
+	}
+	if user, pass, ok := (&http.Request{Header: headers}).BasicAuth(); !ok || user != "user" || pass != "pass" {
+		t.Errorf("got basic auth %q %q, want user pass", user, pass)
+	}
+	decoded, err := snappy.Decode(nil, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request prompb.WriteRequest
+	if err := request.Unmarshal(decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]map[string]string{
+		"0xaa": {"__name__": "validator_health_balance_gwei", "pubkey": "0xaa", "group": "client-a"},
+		"0xbb": {"__name__": "validator_health_balance_gwei", "pubkey": "0xbb", "group": strings.Repeat("b", 70000)},
+	}
+	values := map[string]float64{"0xaa": 32000000000, "0xbb": 31.5}
+	if len(request.Timeseries) != len(want) {
+		t.Fatalf("got %d series, want %d", len(request.Timeseries), len(want))
+	}
+	for _, series := range request.Timeseries {
+		labels := make(map[string]string)
+		for _, label := range series.Labels {
+			labels[label.Name] = label.Value
+		}
+		pubkey := labels["pubkey"]
+		wantLabels, ok := want[pubkey]
+		if !ok {
+			t.Errorf("got unexpected series %v", pubkey)
+			continue
+		}
+		if len(series.Samples) != 1 || series.Samples[0].Value != values[pubkey] || series.Samples[0].Timestamp == 0 {
+			t.Errorf("%s got samples %v, want %v now", pubkey, series.Samples, values[pubkey])
+		}
+		if len(labels) != len(wantLabels) {
+			t.Errorf("%s got %d labels, want %d", pubkey, len(labels), len(wantLabels))
+		}
+		for name, value := range wantLabels {
+			if labels[name] != value {
+				t.Errorf("%s got %s=%.20q, want %.20q", pubkey, name, labels[name], value)
+			}
+		}
+	}
+}
+
+func TestRemoteWriteError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client, err := New(WithAddress(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.RemoteWrite(context.Background(), testGatherer(t)); err == nil {
+		t.Error("expected an error for a 400 response")
+	}
+}
@@ -2,6 +2,7 @@ package prom
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"time"
 
@@ -43,20 +44,43 @@ func (c *Client) QueryRange(ctx context.Context, q string, start, end time.Time,
 	return result, nil
 }
 
-func (c *Client) GetValidatorPubkeys(ctx context.Context) ([]string, error) {
-	resp, err := c.QueryInstant(ctx, `validator_statuses{pubkey!="", node_network="mainnet"} != 0`) // don't check inactive validators
+// ValidatorSeries is a validator discovered in prometheus along with the labels of its series
+type ValidatorSeries struct {
+	Pubkey string
+	Labels map[string]string
+}
+
+func (c *Client) GetValidators(ctx context.Context) ([]ValidatorSeries, error) {
+	resp, err := c.QueryInstant(ctx, c.discovery.PromQL())
 	if err != nil {
 		return nil, err
 	}
+	pubkeyLabel := model.LabelName(c.discovery.PubkeyLabel)
 
-	var pubkeys []string
-	validatorVec := resp.(model.Vector)
+	var validators []ValidatorSeries
+	seen := make(map[string]bool)
+	validatorVec, ok := resp.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("discovery query returned %s, expected a vector", resp.Type())
+	}
 	for _, v := range validatorVec {
+		pubkey := string(v.Metric[pubkeyLabel])
+		if pubkey == "" || seen[pubkey] {
+			continue
+		}
+		seen[pubkey] = true
+
+		labels := make(map[string]string)
 		for name, value := range v.Metric {
-			if string(name) == "pubkey" {
-				pubkeys = append(pubkeys, string(value))
+			if name == model.MetricNameLabel || name == pubkeyLabel {
+				continue
 			}
+			labels[string(name)] = string(value)
 		}
+		validators = append(validators, ValidatorSeries{
+			Pubkey: pubkey,
+			Labels: labels,
+		})
 	}
-	return pubkeys, nil
+	return validators, nil
 }